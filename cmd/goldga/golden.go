@@ -0,0 +1,243 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/tommy351/goldga"
+)
+
+// nolint: gochecknoglobals
+var (
+	listCommand = &command{
+		Name:        "list",
+		Usage:       "[path]",
+		Description: "List snapshots in golden files",
+		Run:         runList,
+	}
+
+	showCommand = &command{
+		Name:        "show",
+		Usage:       "<file> <key>",
+		Description: "Print a snapshot",
+		Run:         runShow,
+	}
+
+	rmCommand = &command{
+		Name:        "rm",
+		Usage:       "<file> <pattern>...",
+		Description: "Remove snapshots matching glob patterns",
+		Run:         runRm,
+	}
+
+	fmtCommand = &command{
+		Name:        "fmt",
+		Usage:       "[path]",
+		Description: "Sort and encode golden files again",
+		Run:         runFmt,
+	}
+
+	migrateCommand = &command{
+		Name:        "migrate",
+		Usage:       "[path]",
+		Description: "Upgrade golden files to the current format",
+		Run:         runMigrate,
+	}
+
+	verifyCommand = &command{
+		Name:        "verify",
+		Usage:       "[path]",
+		Description: "Check that golden files can be parsed",
+		Run:         runVerify,
+	}
+)
+
+func newSuiteStorage(app *app, path, name string) *goldga.SuiteStorage {
+	return &goldga.SuiteStorage{
+		Path: path,
+		Name: name,
+		Fs:   app.Fs,
+	}
+}
+
+func runList(app *app, args []string) error {
+	files, err := goldga.FindGoldenFiles(app.Fs, getDir(args))
+	if err != nil {
+		return err
+	}
+
+	for _, path := range files {
+		keys, err := newSuiteStorage(app, path, "").Keys()
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+
+		fmt.Fprintln(app.Stdout, path)
+
+		for _, key := range keys {
+			fmt.Fprintf(app.Stdout, "  %q\n", key)
+		}
+	}
+
+	return nil
+}
+
+func runShow(app *app, args []string) error {
+	if len(args) != 2 {
+		return errors.New("usage: goldga show <file> <key>")
+	}
+
+	storage := newSuiteStorage(app, args[0], args[1])
+	storage.KeepLineEndings = true
+
+	data, err := storage.Read()
+	if err != nil {
+		return fmt.Errorf("%s: %q: %w", args[0], args[1], err)
+	}
+
+	if _, err := app.Stdout.Write(data); err != nil {
+		return fmt.Errorf("write error: %w", err)
+	}
+
+	return nil
+}
+
+func runRm(app *app, args []string) error {
+	if len(args) < 2 {
+		return errors.New("usage: goldga rm <file> <pattern>...")
+	}
+
+	storage := newSuiteStorage(app, args[0], "")
+
+	keys, err := storage.Keys()
+	if err != nil {
+		return fmt.Errorf("%s: %w", storage.Path, err)
+	}
+
+	var matched []string
+
+	for _, key := range keys {
+		for _, pattern := range args[1:] {
+			if matchGlob(pattern, key) {
+				matched = append(matched, key)
+
+				break
+			}
+		}
+	}
+
+	if len(matched) == 0 {
+		fmt.Fprintln(app.Stdout, "No snapshots matched")
+
+		return nil
+	}
+
+	if err := storage.Delete(matched...); err != nil {
+		return fmt.Errorf("%s: %w", storage.Path, err)
+	}
+
+	for _, key := range matched {
+		fmt.Fprintf(app.Stdout, "Removed %q\n", key)
+	}
+
+	return nil
+}
+
+// matchGlob reports whether the key matches the pattern, in which "*" matches
+// any characters including "/" and "?" matches a single character.
+func matchGlob(pattern, key string) bool {
+	var b strings.Builder
+
+	b.WriteString("^")
+
+	for _, r := range pattern {
+		switch r {
+		case '*':
+			b.WriteString(".*")
+		case '?':
+			b.WriteString(".")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+
+	b.WriteString("$")
+
+	return regexp.MustCompile(b.String()).MatchString(key)
+}
+
+func runFmt(app *app, args []string) error {
+	files, err := goldga.FindGoldenFiles(app.Fs, getDir(args))
+	if err != nil {
+		return err
+	}
+
+	for _, path := range files {
+		if err := newSuiteStorage(app, path, "").Format(); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	}
+
+	fmt.Fprintf(app.Stdout, "%d golden file(s) formatted\n", len(files))
+
+	return nil
+}
+
+func runVerify(app *app, args []string) error {
+	files, err := goldga.FindGoldenFiles(app.Fs, getDir(args))
+	if err != nil {
+		return err
+	}
+
+	invalid := 0
+
+	for _, path := range files {
+		if _, err := newSuiteStorage(app, path, "").Keys(); err != nil {
+			fmt.Fprintf(app.Stdout, "%s: %v\n", path, err)
+			invalid++
+		}
+	}
+
+	if invalid > 0 {
+		return fmt.Errorf("%d of %d golden file(s) are invalid", invalid, len(files))
+	}
+
+	fmt.Fprintf(app.Stdout, "%d golden file(s) verified\n", len(files))
+
+	return nil
+}
+
+func runMigrate(app *app, args []string) error {
+	files, err := goldga.FindGoldenFiles(app.Fs, getDir(args))
+	if err != nil {
+		return err
+	}
+
+	migrated := 0
+
+	for _, path := range files {
+		storage := newSuiteStorage(app, path, "")
+
+		version, err := storage.Version()
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+
+		if version >= goldga.FormatVersion {
+			continue
+		}
+
+		if err := storage.Format(); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+
+		fmt.Fprintf(app.Stdout, "%s: version %d → %d\n", path, version, goldga.FormatVersion)
+		migrated++
+	}
+
+	fmt.Fprintf(app.Stdout, "%d of %d golden file(s) migrated\n", migrated, len(files))
+
+	return nil
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/afero"
+	"github.com/tommy351/goldga"
+)
+
+var _ = DescribeTable("matchGlob", func(pattern, key string, expected bool) {
+	Expect(matchGlob(pattern, key)).To(Equal(expected))
+},
+	Entry("exact", "foo", "foo", true),
+	Entry("different", "foo", "bar", false),
+	Entry("prefix only", "foo", "foo bar", false),
+	Entry("star", "foo*", "foo bar", true),
+	Entry("star matches empty", "foo*", "foo", true),
+	Entry("star matches slash", "a*c", "a/b/c", true),
+	Entry("question mark", "fo?", "foo", true),
+	Entry("question mark requires a character", "fo?", "fo", false),
+	Entry("regexp characters", "a.b (c)", "a.b (c)", true),
+	Entry("escaped dot", "a.b", "axb", false),
+)
+
+var _ = Describe("golden file commands", func() {
+	const path = "/work/testdata/foo.golden"
+
+	var fs afero.Fs
+
+	newStorage := func(name string) *goldga.SuiteStorage {
+		return &goldga.SuiteStorage{Path: path, Name: name, Fs: fs}
+	}
+
+	BeforeEach(func() {
+		fs = afero.NewMemMapFs()
+
+		for _, name := range []string{"foo 1", "foo 2", "bar"} {
+			Expect(newStorage(name).Write([]byte(name))).To(Succeed())
+		}
+	})
+
+	Describe("rm", func() {
+		It("should remove snapshots matching patterns", func() {
+			output, err := runApp(fs, "", "rm", path, "foo *")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(output).To(Equal("Removed \"foo 1\"\nRemoved \"foo 2\"\n"))
+			Expect(newStorage("").Keys()).To(Equal([]string{"bar"}))
+		})
+
+		It("should not modify the file when nothing matches", func() {
+			output, err := runApp(fs, "", "rm", path, "baz")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(output).To(Equal("No snapshots matched\n"))
+			Expect(newStorage("").Keys()).To(Equal([]string{"bar", "foo 1", "foo 2"}))
+		})
+
+		It("should return error when patterns are missing", func() {
+			_, err := runApp(fs, "", "rm", path)
+			Expect(err).To(MatchError(ContainSubstring("usage: goldga rm")))
+		})
+	})
+
+	Describe("fmt", func() {
+		It("should sort and encode golden files again", func() {
+			Expect(afero.WriteFile(fs, path, []byte("[snapshots]\nb = 'b'\na = 'a'\n"), 0o644)).To(Succeed())
+
+			output, err := runApp(fs, "", "fmt", "/work")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(output).To(Equal("1 golden file(s) formatted\n"))
+
+			content, err := afero.ReadFile(fs, path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(MatchRegexp(`(?s)version = \d+\n\[snapshots\]\n"a" = '''\na'''\n"b" = '''\nb'''\n$`))
+		})
+	})
+
+	Describe("verify", func() {
+		It("should succeed when golden files are valid", func() {
+			output, err := runApp(fs, "", "verify", "/work")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(output).To(Equal("1 golden file(s) verified\n"))
+		})
+
+		It("should report invalid golden files", func() {
+			invalid := "/work/testdata/invalid.golden"
+			Expect(afero.WriteFile(fs, invalid, []byte("[snapshots"), 0o644)).To(Succeed())
+
+			output, err := runApp(fs, "", "verify", "/work")
+			Expect(err).To(MatchError("1 of 2 golden file(s) are invalid"))
+			Expect(output).To(HavePrefix(invalid + ": "))
+		})
+	})
+})
@@ -0,0 +1,86 @@
+// Command goldga manages golden files created by goldga.
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/afero"
+)
+
+type app struct {
+	Fs     afero.Fs
+	Stdin  io.Reader
+	Stdout io.Writer
+}
+
+type command struct {
+	Name        string
+	Usage       string
+	Description string
+	Run         func(app *app, args []string) error
+}
+
+// nolint: gochecknoglobals
+var commands = []*command{
+	listCommand,
+	showCommand,
+	rmCommand,
+	fmtCommand,
+	migrateCommand,
+	verifyCommand,
+	pendingCommand,
+	reviewCommand,
+	acceptCommand,
+	rejectCommand,
+}
+
+func (a *app) usage() {
+	fmt.Fprintln(a.Stdout, "Usage: goldga <command> [arguments]")
+	fmt.Fprintln(a.Stdout)
+	fmt.Fprintln(a.Stdout, "Commands:")
+
+	for _, cmd := range commands {
+		fmt.Fprintf(a.Stdout, "  %-30s %s\n", cmd.Name+" "+cmd.Usage, cmd.Description)
+	}
+}
+
+func (a *app) run(args []string) error {
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		a.usage()
+
+		return nil
+	}
+
+	for _, cmd := range commands {
+		if cmd.Name == args[0] {
+			return cmd.Run(a, args[1:])
+		}
+	}
+
+	return fmt.Errorf("unknown command %q", args[0])
+}
+
+// getDir returns the directory argument, which defaults to the working directory.
+func getDir(args []string) string {
+	if len(args) > 0 && strings.TrimSpace(args[0]) != "" {
+		return args[0]
+	}
+
+	return "."
+}
+
+func main() {
+	a := &app{
+		Fs:     afero.NewOsFs(),
+		Stdin:  os.Stdin,
+		Stdout: os.Stdout,
+	}
+
+	if err := a.run(os.Args[1:]); err != nil {
+		fmt.Fprintf(os.Stderr, "goldga: %v\n", err)
+		os.Exit(1)
+	}
+}
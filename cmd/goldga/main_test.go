@@ -0,0 +1,44 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/afero"
+)
+
+func Test(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "goldga")
+}
+
+// runApp runs the app with the given file system and input, and returns the
+// output.
+func runApp(fs afero.Fs, stdin string, args ...string) (string, error) {
+	var stdout bytes.Buffer
+
+	a := &app{
+		Fs:     fs,
+		Stdin:  strings.NewReader(stdin),
+		Stdout: &stdout,
+	}
+	err := a.run(args)
+
+	return stdout.String(), err
+}
+
+var _ = Describe("app", func() {
+	It("should print usage", func() {
+		output, err := runApp(afero.NewMemMapFs(), "", "help")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(output).To(HavePrefix("Usage: goldga <command> [arguments]"))
+	})
+
+	It("should return error when the command is unknown", func() {
+		_, err := runApp(afero.NewMemMapFs(), "", "foo")
+		Expect(err).To(MatchError(`unknown command "foo"`))
+	})
+})
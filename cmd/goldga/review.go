@@ -0,0 +1,147 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"strings"
+
+	"github.com/tommy351/goldga"
+)
+
+// nolint: gochecknoglobals
+var (
+	pendingCommand = &command{
+		Name:        "pending",
+		Usage:       "[dir]",
+		Description: "List pending snapshots",
+		Run:         runPending,
+	}
+
+	reviewCommand = &command{
+		Name:        "review",
+		Usage:       "[dir]",
+		Description: "Review pending snapshots one by one",
+		Run:         runReview,
+	}
+
+	acceptCommand = &command{
+		Name:        "accept",
+		Usage:       "[dir]",
+		Description: "Accept all pending snapshots",
+		Run: func(app *app, args []string) error {
+			return forEachPending(app, args, (*goldga.PendingSnapshot).Accept)
+		},
+	}
+
+	rejectCommand = &command{
+		Name:        "reject",
+		Usage:       "[dir]",
+		Description: "Reject all pending snapshots",
+		Run: func(app *app, args []string) error {
+			return forEachPending(app, args, (*goldga.PendingSnapshot).Reject)
+		},
+	}
+)
+
+func runPending(app *app, args []string) error {
+	snapshots, err := goldga.FindPendingSnapshots(app.Fs, getDir(args))
+	if err != nil {
+		return err
+	}
+
+	for _, s := range snapshots {
+		fmt.Fprintf(app.Stdout, "%s: %q\n", s.Path, s.Name)
+	}
+
+	return nil
+}
+
+func forEachPending(app *app, args []string, fn func(s *goldga.PendingSnapshot) error) error {
+	snapshots, err := goldga.FindPendingSnapshots(app.Fs, getDir(args))
+	if err != nil {
+		return err
+	}
+
+	for _, s := range snapshots {
+		if err := fn(s); err != nil {
+			return fmt.Errorf("%s: %q: %w", s.Path, s.Name, err)
+		}
+	}
+
+	fmt.Fprintf(app.Stdout, "%d snapshot(s) processed\n", len(snapshots))
+
+	return nil
+}
+
+func runReview(app *app, args []string) error {
+	snapshots, err := goldga.FindPendingSnapshots(app.Fs, getDir(args))
+	if err != nil {
+		return err
+	}
+
+	scanner := bufio.NewScanner(app.Stdin)
+
+	for i, s := range snapshots {
+		current, pending, err := s.Read()
+		if err != nil {
+			return fmt.Errorf("%s: %q: %w", s.Path, s.Name, err)
+		}
+
+		fmt.Fprintf(app.Stdout, "[%d/%d] %s: %q\n", i+1, len(snapshots), s.Path, s.Name)
+
+		if current == nil {
+			fmt.Fprintln(app.Stdout, "New snapshot")
+		}
+
+		fmt.Fprintf(app.Stdout, "%s\n\n", getDiffer(s).Diff(current, pending))
+
+		action, err := askAction(app, scanner)
+		if err != nil {
+			return err
+		}
+
+		switch action {
+		case "a":
+			err = s.Accept()
+		case "r":
+			err = s.Reject()
+		case "q":
+			return nil
+		}
+
+		if err != nil {
+			return fmt.Errorf("%s: %q: %w", s.Path, s.Name, err)
+		}
+	}
+
+	return nil
+}
+
+func getDiffer(s *goldga.PendingSnapshot) goldga.Differ {
+	if s.Binary {
+		return &goldga.HexDiffer{}
+	}
+
+	return goldga.DefaultDiffer
+}
+
+// askAction prompts until the user enters a valid action and returns its
+// first letter.
+func askAction(app *app, scanner *bufio.Scanner) (string, error) {
+	for {
+		fmt.Fprint(app.Stdout, "[a]ccept, [r]eject, [s]kip or [q]uit? ")
+
+		if !scanner.Scan() {
+			if err := scanner.Err(); err != nil {
+				return "", fmt.Errorf("failed to read input: %w", err)
+			}
+
+			return "q", nil
+		}
+
+		switch input := strings.ToLower(strings.TrimSpace(scanner.Text())); input {
+		case "a", "accept", "r", "reject", "s", "skip", "q", "quit":
+			return input[:1], nil
+		}
+	}
+}
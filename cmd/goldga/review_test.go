@@ -0,0 +1,106 @@
+package main
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/afero"
+	"github.com/tommy351/goldga"
+)
+
+var _ = Describe("review commands", func() {
+	const path = "/work/testdata/foo.golden"
+
+	var fs afero.Fs
+
+	newStorage := func(name string) *goldga.SuiteStorage {
+		return &goldga.SuiteStorage{Path: path, Name: name, Fs: fs}
+	}
+
+	readSnapshot := func(name string) ([]byte, error) {
+		return newStorage(name).Read()
+	}
+
+	pendingKeys := func() ([]string, error) {
+		return (&goldga.SuiteStorage{Path: path + goldga.PendingFileSuffix, Fs: fs}).Keys()
+	}
+
+	BeforeEach(func() {
+		fs = afero.NewMemMapFs()
+
+		Expect(newStorage("A").Write([]byte("a"))).To(Succeed())
+		Expect(newStorage("A").WritePending([]byte("new a"))).To(Succeed())
+		Expect(newStorage("B").WritePending([]byte("new b"))).To(Succeed())
+	})
+
+	Describe("pending", func() {
+		It("should list pending snapshots", func() {
+			output, err := runApp(fs, "", "pending", "/work")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(output).To(Equal(path + `: "A"` + "\n" + path + `: "B"` + "\n"))
+		})
+	})
+
+	Describe("accept", func() {
+		It("should accept all pending snapshots", func() {
+			output, err := runApp(fs, "", "accept", "/work")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(output).To(Equal("2 snapshot(s) processed\n"))
+			Expect(readSnapshot("A")).To(Equal([]byte("new a")))
+			Expect(readSnapshot("B")).To(Equal([]byte("new b")))
+			Expect(afero.Exists(fs, path+goldga.PendingFileSuffix)).To(BeFalse())
+		})
+	})
+
+	Describe("reject", func() {
+		It("should reject all pending snapshots", func() {
+			output, err := runApp(fs, "", "reject", "/work")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(output).To(Equal("2 snapshot(s) processed\n"))
+			Expect(readSnapshot("A")).To(Equal([]byte("a")))
+			Expect(readSnapshot("B")).Error().To(MatchError(afero.ErrFileNotFound))
+			Expect(afero.Exists(fs, path+goldga.PendingFileSuffix)).To(BeFalse())
+		})
+	})
+
+	Describe("review", func() {
+		It("should apply actions in order", func() {
+			output, err := runApp(fs, "skip\nr\n", "review", "/work")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(output).To(ContainSubstring(`[1/2] ` + path + `: "A"`))
+			Expect(output).To(ContainSubstring(`[2/2] ` + path + `: "B"` + "\nNew snapshot\n"))
+			Expect(readSnapshot("A")).To(Equal([]byte("a")))
+			Expect(pendingKeys()).To(Equal([]string{"A"}))
+		})
+
+		It("should accept snapshots", func() {
+			_, err := runApp(fs, "a\naccept\n", "review", "/work")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(readSnapshot("A")).To(Equal([]byte("new a")))
+			Expect(readSnapshot("B")).To(Equal([]byte("new b")))
+		})
+
+		It("should ask again when the action is invalid", func() {
+			output, err := runApp(fs, "x\nq\n", "review", "/work")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(output).To(ContainSubstring("? [a]ccept, [r]eject, [s]kip or [q]uit? "))
+			Expect(pendingKeys()).To(Equal([]string{"A", "B"}))
+		})
+
+		It("should quit at the end of input", func() {
+			output, err := runApp(fs, "", "review", "/work")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(output).NotTo(ContainSubstring("[2/2]"))
+			Expect(pendingKeys()).To(Equal([]string{"A", "B"}))
+		})
+
+		It("should show hexdumps of binary snapshots", func() {
+			storage := newStorage("A")
+			storage.Binary = true
+			Expect(storage.WritePending([]byte{0x00, 0xff})).To(Succeed())
+
+			output, err := runApp(fs, "q\n", "review", "/work")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(output).To(ContainSubstring("- Snapshot (1 bytes)\n+ Received (2 bytes)"))
+		})
+	})
+})
@@ -0,0 +1,192 @@
+package goldga
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// nolint: gochecknoglobals
+var (
+	DefaultComparator Comparator = &BytesComparator{}
+
+	whitespacePattern = regexp.MustCompile(`[ \t]+`)
+	numberPattern     = regexp.MustCompile(`[-+]?(?:\d+\.?\d*|\.\d+)(?:[eE][-+]?\d+)?`)
+)
+
+// Comparator decides whether the received content matches the snapshot.
+type Comparator interface {
+	Compare(snapshot, received []byte) (bool, error)
+}
+
+// Normalizer can be implemented by a Comparator to pass a normalized view of
+// the content to the Differ, so the diff only shows meaningful changes.
+type Normalizer interface {
+	Normalize(data []byte) ([]byte, error)
+}
+
+var _ Comparator = (*BytesComparator)(nil)
+
+// BytesComparator matches content which is exactly the same.
+type BytesComparator struct{}
+
+func (BytesComparator) Compare(snapshot, received []byte) (bool, error) {
+	return bytes.Equal(snapshot, received), nil
+}
+
+var (
+	_ Comparator = (*JSONComparator)(nil)
+	_ Normalizer = (*JSONComparator)(nil)
+)
+
+// JSONComparator matches JSON documents which are semantically equal, e.g.
+// objects with different key order or indentation.
+type JSONComparator struct{}
+
+func (JSONComparator) decode(data []byte) (interface{}, error) {
+	var result interface{}
+
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("json decode error: %w", err)
+	}
+
+	return result, nil
+}
+
+func (c JSONComparator) Compare(snapshot, received []byte) (bool, error) {
+	a, err := c.decode(snapshot)
+	if err != nil {
+		return false, nil
+	}
+
+	b, err := c.decode(received)
+	if err != nil {
+		return false, nil
+	}
+
+	return reflect.DeepEqual(a, b), nil
+}
+
+// Normalize indents the document and sorts object keys.
+func (c JSONComparator) Normalize(data []byte) ([]byte, error) {
+	v, err := c.decode(data)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("json encode error: %w", err)
+	}
+
+	return append(result, '\n'), nil
+}
+
+var (
+	_ Comparator = (*YAMLComparator)(nil)
+	_ Normalizer = (*YAMLComparator)(nil)
+)
+
+// YAMLComparator matches YAML documents which are semantically equal.
+type YAMLComparator struct{}
+
+func (YAMLComparator) Compare(snapshot, received []byte) (bool, error) {
+	a, err := unmarshalYAML(snapshot)
+	if err != nil {
+		return false, nil
+	}
+
+	b, err := unmarshalYAML(received)
+	if err != nil {
+		return false, nil
+	}
+
+	return reflect.DeepEqual(a, b), nil
+}
+
+// Normalize sorts map keys and formats the document consistently.
+func (YAMLComparator) Normalize(data []byte) ([]byte, error) {
+	v, err := unmarshalYAML(data)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := yaml.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("yaml encode error: %w", err)
+	}
+
+	return result, nil
+}
+
+var (
+	_ Comparator = (*WhitespaceComparator)(nil)
+	_ Normalizer = (*WhitespaceComparator)(nil)
+)
+
+// WhitespaceComparator ignores line endings, indentation, runs of spaces and
+// tabs, and blank lines at the beginning or the end of the content.
+type WhitespaceComparator struct{}
+
+func (c WhitespaceComparator) Compare(snapshot, received []byte) (bool, error) {
+	a, _ := c.Normalize(snapshot)
+	b, _ := c.Normalize(received)
+
+	return bytes.Equal(a, b), nil
+}
+
+func (WhitespaceComparator) Normalize(data []byte) ([]byte, error) {
+	lines := strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")
+
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(whitespacePattern.ReplaceAllString(line, " "))
+	}
+
+	return []byte(strings.Trim(strings.Join(lines, "\n"), "\n")), nil
+}
+
+var _ Comparator = (*NumericComparator)(nil)
+
+// NumericComparator matches content in which numbers differ by at most
+// Tolerance, e.g. floats printed with rounding errors. Text other than numbers
+// must be the same.
+type NumericComparator struct {
+	Tolerance float64
+}
+
+func (c *NumericComparator) Compare(snapshot, received []byte) (bool, error) {
+	a, b := string(snapshot), string(received)
+
+	if numberPattern.ReplaceAllString(a, "0") != numberPattern.ReplaceAllString(b, "0") {
+		return false, nil
+	}
+
+	numbersA := numberPattern.FindAllString(a, -1)
+	numbersB := numberPattern.FindAllString(b, -1)
+
+	for i := range numbersA {
+		x, errX := strconv.ParseFloat(numbersA[i], 64)
+		y, errY := strconv.ParseFloat(numbersB[i], 64)
+
+		if errX != nil || errY != nil {
+			if numbersA[i] != numbersB[i] {
+				return false, nil
+			}
+
+			continue
+		}
+
+		if math.Abs(x-y) > c.Tolerance {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
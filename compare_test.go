@@ -0,0 +1,76 @@
+package goldga
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("JSONComparator", func() {
+	comparator := &JSONComparator{}
+
+	DescribeTable("Compare", func(a, b string, expected bool) {
+		Expect(comparator.Compare([]byte(a), []byte(b))).To(Equal(expected))
+	},
+		Entry("key order", `{"a":1,"b":2}`, `{"b":2, "a":1}`, true),
+		Entry("indentation", `[1,2]`, "[\n  1,\n  2\n]\n", true),
+		Entry("number format", `1.0`, `1`, true),
+		Entry("different values", `{"a":1}`, `{"a":2}`, false),
+		Entry("invalid JSON", `{`, `{`, false),
+	)
+
+	It("should normalize documents", func() {
+		Expect(comparator.Normalize([]byte(`{"b":[1],"a":true}`))).To(Equal([]byte(`{
+  "a": true,
+  "b": [
+    1
+  ]
+}
+`)))
+	})
+})
+
+var _ = Describe("YAMLComparator", func() {
+	comparator := &YAMLComparator{}
+
+	DescribeTable("Compare", func(a, b string, expected bool) {
+		Expect(comparator.Compare([]byte(a), []byte(b))).To(Equal(expected))
+	},
+		Entry("key order", "a: 1\nb: 2\n", "b: 2\na: 1\n", true),
+		Entry("flow style", "a: [1, 2]\n", "a:\n- 1\n- 2\n", true),
+		Entry("different values", "a: 1\n", "a: 2\n", false),
+	)
+
+	It("should normalize documents", func() {
+		Expect(comparator.Normalize([]byte("b: 1\na: {c: 2}\n"))).To(Equal([]byte("a:\n  c: 2\nb: 1\n")))
+	})
+})
+
+var _ = Describe("WhitespaceComparator", func() {
+	comparator := &WhitespaceComparator{}
+
+	DescribeTable("Compare", func(a, b string, expected bool) {
+		Expect(comparator.Compare([]byte(a), []byte(b))).To(Equal(expected))
+	},
+		Entry("line endings", "a\r\nb\r\n", "a\nb\n", true),
+		Entry("indentation", "a\n  b", "a\n\tb", true),
+		Entry("spaces", "a  b\t c ", "a b c", true),
+		Entry("trailing newlines", "a\n\n", "a", true),
+		Entry("different lines", "a b", "a\nb", false),
+		Entry("different text", "a", "b", false),
+	)
+})
+
+var _ = Describe("NumericComparator", func() {
+	comparator := &NumericComparator{Tolerance: 0.01}
+
+	DescribeTable("Compare", func(a, b string, expected bool) {
+		Expect(comparator.Compare([]byte(a), []byte(b))).To(Equal(expected))
+	},
+		Entry("same", "x = 1.5", "x = 1.5", true),
+		Entry("within tolerance", "x = 0.30000000000000004, y = 2", "x = 0.3, y = 2.001", true),
+		Entry("exponent", "1e-3", "0.001", true),
+		Entry("out of tolerance", "x = 1.5", "x = 1.6", false),
+		Entry("different text", "x = 1.5", "y = 1.5", false),
+		Entry("different number of values", "1 2", "1", false),
+	)
+})
@@ -0,0 +1,319 @@
+package goldga
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/BurntSushi/toml"
+	"github.com/spf13/afero"
+)
+
+// ConfigFileName is the name of the config file in the module root.
+const ConfigFileName = "goldga.toml"
+
+// nolint: gochecknoglobals
+var defaultConfigStore = &configStore{}
+
+// Config holds defaults of every matcher in a suite. Environment variables,
+// e.g. UPDATE_GOLDEN, take precedence over the config.
+type Config struct {
+	// Options are applied before options passed to Match.
+	Options []Option
+
+	// UpdateMode is used when UPDATE_GOLDEN is not set.
+	UpdateMode UpdateMode
+
+	// Scrubbers are applied to every snapshot, before scrubbers added by
+	// WithScrubbers.
+	Scrubbers []Scrubber
+
+	// PathResolver overrides DefaultPathResolver.
+	PathResolver PathResolver
+
+	// Layout is used when GOLDGA_LAYOUT is not set.
+	Layout Layout
+}
+
+// Configure registers the config of the suite. It replaces the config
+// registered before, and is merged with goldga.toml in the module root, in
+// which fields set in the given config take precedence. It always returns
+// true, so it can be called at the top level:
+//
+//	var _ = goldga.Configure(goldga.Config{
+//		Options: []goldga.Option{goldga.WithSerializer(&goldga.JSONSerializer{})},
+//	})
+func Configure(config Config) bool {
+	defaultConfigStore.set(&config)
+
+	return true
+}
+
+// LoadConfig reads a config file.
+func LoadConfig(path string) (*Config, error) {
+	return loadConfigFile(defaultFs, path)
+}
+
+func getConfig() *Config {
+	return defaultConfigStore.get()
+}
+
+type configStore struct {
+	mu       sync.RWMutex
+	config   *Config
+	fileOnce sync.Once
+	file     *Config
+}
+
+func (s *configStore) set(config *Config) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.config = config
+}
+
+func (s *configStore) get() *Config {
+	s.fileOnce.Do(func() {
+		config, err := findConfigFile(defaultFs)
+		if err != nil {
+			panic(fmt.Errorf("failed to load %s: %w", ConfigFileName, err))
+		}
+
+		s.file = config
+	})
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return mergeConfig(s.file, s.config)
+}
+
+// mergeConfig returns a config in which fields of override take precedence.
+// Options and scrubbers are appended.
+func mergeConfig(base, override *Config) *Config {
+	result := &Config{}
+
+	for _, c := range []*Config{base, override} {
+		if c == nil {
+			continue
+		}
+
+		result.Options = append(result.Options, c.Options...)
+		result.Scrubbers = append(result.Scrubbers, c.Scrubbers...)
+
+		if c.UpdateMode != "" {
+			result.UpdateMode = c.UpdateMode
+		}
+
+		if c.PathResolver != nil {
+			result.PathResolver = c.PathResolver
+		}
+
+		if c.Layout != "" {
+			result.Layout = c.Layout
+		}
+	}
+
+	return result
+}
+
+func (c *Config) getPathResolver() PathResolver {
+	if c.PathResolver != nil {
+		return c.PathResolver
+	}
+
+	return DefaultPathResolver
+}
+
+// findConfigFile loads the config file in the module root, which is the
+// closest parent directory of the working directory containing go.mod. It
+// returns nil when the file does not exist.
+func findConfigFile(fs afero.Fs) (*Config, error) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get working directory: %w", err)
+	}
+
+	for {
+		if exists, err := afero.Exists(fs, filepath.Join(dir, "go.mod")); err != nil {
+			return nil, fmt.Errorf("failed to check file exist: %w", err)
+		} else if exists {
+			break
+		}
+
+		parent := filepath.Dir(dir)
+
+		if parent == dir {
+			return nil, nil
+		}
+
+		dir = parent
+	}
+
+	config, err := loadConfigFile(fs, filepath.Join(dir, ConfigFileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+
+	return config, err
+}
+
+type configFile struct {
+	UpdateMode      string                 `toml:"update_mode"`
+	Layout          string                 `toml:"layout"`
+	Serializer      string                 `toml:"serializer"`
+	Scrubbers       []string               `toml:"scrubbers"`
+	RegexpScrubbers []regexpScrubberConfig `toml:"regexp_scrubbers"`
+	Path            pathConfig             `toml:"path"`
+}
+
+type regexpScrubberConfig struct {
+	Pattern     string `toml:"pattern"`
+	Replacement string `toml:"replacement"`
+}
+
+type pathConfig struct {
+	Resolver string `toml:"resolver"`
+	Dir      string `toml:"dir"`
+	Name     string `toml:"name"`
+	Template string `toml:"template"`
+}
+
+func loadConfigFile(fs afero.Fs, path string) (*Config, error) {
+	content, err := afero.ReadFile(fs, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	var file configFile
+
+	meta, err := toml.Decode(string(content), &file)
+	if err != nil {
+		return nil, fmt.Errorf("toml decode error: %w", err)
+	}
+
+	if undecoded := meta.Undecoded(); len(undecoded) > 0 {
+		keys := make([]string, len(undecoded))
+
+		for i, key := range undecoded {
+			keys[i] = key.String()
+		}
+
+		sort.Strings(keys)
+
+		return nil, fmt.Errorf("unknown keys: %s", strings.Join(keys, ", "))
+	}
+
+	return file.toConfig()
+}
+
+func (f *configFile) toConfig() (*Config, error) {
+	config := &Config{}
+	var err error
+
+	if f.UpdateMode != "" {
+		if config.UpdateMode, err = ParseUpdateMode(f.UpdateMode); err != nil {
+			return nil, err
+		}
+	}
+
+	if f.Layout != "" {
+		if config.Layout, err = ParseLayout(f.Layout); err != nil {
+			return nil, err
+		}
+	}
+
+	if f.Serializer != "" {
+		serializer, err := getSerializerByName(f.Serializer)
+		if err != nil {
+			return nil, err
+		}
+
+		config.Options = append(config.Options, WithSerializer(serializer))
+	}
+
+	for _, name := range f.Scrubbers {
+		scrubber, err := getScrubberByName(name)
+		if err != nil {
+			return nil, err
+		}
+
+		config.Scrubbers = append(config.Scrubbers, scrubber)
+	}
+
+	for _, s := range f.RegexpScrubbers {
+		scrubber, err := NewRegexpScrubber(s.Pattern, s.Replacement)
+		if err != nil {
+			return nil, err
+		}
+
+		config.Scrubbers = append(config.Scrubbers, scrubber)
+	}
+
+	if config.PathResolver, err = f.Path.toPathResolver(); err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+func (p *pathConfig) toPathResolver() (PathResolver, error) {
+	switch p.Resolver {
+	case "":
+		return nil, nil
+	case "testdata":
+		return &TestdataPathResolver{}, nil
+	case "mirror":
+		return &MirrorPathResolver{Dir: p.Dir}, nil
+	case "package":
+		return &PackagePathResolver{Name: p.Name}, nil
+	case "template":
+		if p.Template == "" {
+			return nil, errors.New("path template is empty")
+		}
+
+		return &TemplatePathResolver{Template: p.Template}, nil
+	}
+
+	return nil, fmt.Errorf("invalid path resolver %q", p.Resolver)
+}
+
+func getSerializerByName(name string) (Serializer, error) {
+	switch name {
+	case "dump":
+		return DefaultSerializer, nil
+	case "string":
+		return &StringSerializer{}, nil
+	case "json":
+		return &JSONSerializer{}, nil
+	case "yaml":
+		return &YAMLSerializer{}, nil
+	case "toml":
+		return &TOMLSerializer{}, nil
+	}
+
+	return nil, fmt.Errorf("invalid serializer %q", name)
+}
+
+func getScrubberByName(name string) (Scrubber, error) {
+	switch name {
+	case "rfc3339":
+		return ScrubRFC3339, nil
+	case "uuid":
+		return ScrubUUID, nil
+	case "pointer":
+		return ScrubPointer, nil
+	case "duration":
+		return ScrubDuration, nil
+	case "tempdir":
+		return ScrubTempDir, nil
+	case "homedir":
+		return ScrubHomeDir, nil
+	}
+
+	return nil, fmt.Errorf("invalid scrubber %q", name)
+}
@@ -0,0 +1,135 @@
+package goldga
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/afero"
+)
+
+var _ = Describe("loadConfigFile", func() {
+	var fs afero.Fs
+
+	BeforeEach(func() {
+		fs = afero.NewMemMapFs()
+	})
+
+	load := func(content string) (*Config, error) {
+		Expect(afero.WriteFile(fs, ConfigFileName, []byte(content), 0o644)).To(Succeed())
+
+		return loadConfigFile(fs, ConfigFileName)
+	}
+
+	It("should load the config", func() {
+		config, err := load(`
+update_mode = "none"
+layout = "dir"
+serializer = "json"
+scrubbers = ["uuid"]
+
+[[regexp_scrubbers]]
+pattern = 'id=\d+'
+replacement = "id=<id>"
+
+[path]
+resolver = "template"
+template = "__snapshots__/{{.File}}.golden"
+`)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(config.UpdateMode).To(Equal(UpdateNone))
+		Expect(config.Layout).To(Equal(LayoutDir))
+		Expect(config.PathResolver).To(Equal(&TemplatePathResolver{Template: "__snapshots__/{{.File}}.golden"}))
+		Expect(config.Scrubbers).To(HaveLen(2))
+		Expect(config.Scrubbers[0]).To(Equal(ScrubUUID))
+		Expect(config.Scrubbers[1].Scrub([]byte("id=123"))).To(Equal([]byte("id=<id>")))
+		Expect(config.Options).To(HaveLen(1))
+
+		m := &Matcher{}
+		config.Options[0](m)
+		Expect(m.Serializer).To(Equal(&JSONSerializer{}))
+	})
+
+	It("should load an empty config", func() {
+		Expect(load("")).To(Equal(&Config{}))
+	})
+
+	DescribeTable("invalid config", func(content string, expected string) {
+		_, err := load(content)
+		Expect(err).To(MatchError(ContainSubstring(expected)))
+	},
+		Entry("unknown keys", "foo = 1\n[path]\nbar = 2", "unknown keys: foo, path.bar"),
+		Entry("update mode", `update_mode = "foo"`, `invalid update mode "foo"`),
+		Entry("layout", `layout = "foo"`, `invalid layout "foo"`),
+		Entry("serializer", `serializer = "foo"`, `invalid serializer "foo"`),
+		Entry("scrubber", `scrubbers = ["foo"]`, `invalid scrubber "foo"`),
+		Entry("regexp scrubber", "[[regexp_scrubbers]]\npattern = '('", "invalid pattern"),
+		Entry("path resolver", "[path]\nresolver = 'foo'", `invalid path resolver "foo"`),
+		Entry("empty template", "[path]\nresolver = 'template'", "path template is empty"),
+	)
+})
+
+var _ = Describe("mergeConfig", func() {
+	It("should override fields", func() {
+		resolver := &PackagePathResolver{}
+		config := mergeConfig(&Config{
+			UpdateMode:   UpdateNone,
+			Layout:       LayoutDir,
+			PathResolver: &MirrorPathResolver{},
+			Scrubbers:    []Scrubber{ScrubUUID},
+		}, &Config{
+			UpdateMode:   UpdateFailed,
+			PathResolver: resolver,
+			Scrubbers:    []Scrubber{ScrubPointer},
+		})
+
+		Expect(config).To(Equal(&Config{
+			UpdateMode:   UpdateFailed,
+			Layout:       LayoutDir,
+			PathResolver: resolver,
+			Scrubbers:    []Scrubber{ScrubUUID, ScrubPointer},
+		}))
+	})
+
+	It("should return an empty config when both are nil", func() {
+		Expect(mergeConfig(nil, nil)).To(Equal(&Config{}))
+	})
+})
+
+var _ = Describe("Configure", func() {
+	AfterEach(func() {
+		defaultConfigStore.set(nil)
+	})
+
+	It("should set defaults of matchers", func() {
+		if os.Getenv("UPDATE_GOLDEN") != "" || os.Getenv("GOLDGA_LAYOUT") != "" {
+			Skip("environment variables take precedence over the config")
+		}
+
+		Expect(Configure(Config{
+			Options:      []Option{WithSerializer(&YAMLSerializer{})},
+			UpdateMode:   UpdateNone,
+			Scrubbers:    []Scrubber{ScrubUUID},
+			PathResolver: &PackagePathResolver{Name: "foo"},
+			Layout:       LayoutDir,
+		})).To(BeTrue())
+
+		m := Match(WithScrubbers(ScrubPointer))
+		Expect(m.Serializer).To(Equal(&YAMLSerializer{}))
+		Expect(m.UpdateMode).To(Equal(UpdateNone))
+		Expect(m.Scrubbers).To(Equal([]Scrubber{ScrubUUID, ScrubPointer}))
+		Expect(m.Storage.(*DirStorage).Dir).To(Equal(filepath.Join("testdata", "foo")))
+		Expect(m.Storage.(*DirStorage).Ext).To(Equal(".yaml"))
+	})
+
+	It("should not modify scrubbers of the config", func() {
+		scrubbers := make([]Scrubber, 1, 2)
+		scrubbers[0] = ScrubUUID
+		Configure(Config{Scrubbers: scrubbers})
+
+		Match(WithScrubbers(ScrubPointer))
+		Expect(getConfig().Scrubbers).To(Equal([]Scrubber{ScrubUUID}))
+		Expect(scrubbers[:2][1]).To(BeNil())
+	})
+})
@@ -0,0 +1,96 @@
+package goldga
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/onsi/ginkgo/v2"
+)
+
+// nolint: gochecknoglobals
+var defaultSpecCounter = &specCounter{}
+
+// specCounter counts Match calls of the running spec, so multiple snapshots in
+// a spec get different keys.
+type specCounter struct {
+	mu     sync.Mutex
+	spec   string
+	counts map[string]int
+}
+
+// next returns the number of the next snapshot with the given key. Counts are
+// reset when a different spec is running.
+func (c *specCounter) next(spec, key string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.spec != spec || c.counts == nil {
+		c.spec = spec
+		c.counts = map[string]int{}
+	}
+
+	c.counts[key]++
+
+	return c.counts[key]
+}
+
+// getGinkgoSpecID returns an ID of the running spec. The start time and the
+// number of attempts distinguish specs with the same text and retries of
+// FlakeAttempts.
+func getGinkgoSpecID() string {
+	spec := ginkgo.CurrentSpecReport()
+
+	return fmt.Sprintf("%s %s %s #%d", spec.LeafNodeLocation, spec.FullText(), spec.StartTime.Format(time.RFC3339Nano), spec.NumAttempts)
+}
+
+// renameStorage changes the key of snapshots in the storage.
+func renameStorage(storage Storage, rename func(name string) string) {
+	switch s := storage.(type) {
+	case *SuiteStorage:
+		s.Name = rename(s.Name)
+	case *DirStorage:
+		s.Name = rename(s.Name)
+	}
+}
+
+// getStorageName returns the key of snapshots in the storage.
+func getStorageName(storage Storage) (string, bool) {
+	switch s := storage.(type) {
+	case *SuiteStorage:
+		return s.Name, true
+	case *DirStorage:
+		return s.Name, true
+	}
+
+	return "", false
+}
+
+// copyStorage returns a shallow copy of the storage.
+func copyStorage(storage Storage) Storage {
+	switch s := storage.(type) {
+	case *SuiteStorage:
+		c := *s
+
+		return &c
+	case *DirStorage:
+		c := *s
+
+		return &c
+	}
+
+	return storage
+}
+
+// numberSnapshot appends the number of the snapshot to its key. The first
+// snapshot falls back to the key without a number, so golden files written
+// before snapshots were numbered keep matching.
+func numberSnapshot(m *Matcher, n int) {
+	if n == 1 {
+		m.fallback = copyStorage(m.Storage)
+	}
+
+	renameStorage(m.Storage, func(name string) string {
+		return fmt.Sprintf("%s %d", name, n)
+	})
+}
@@ -0,0 +1,77 @@
+package goldga
+
+import (
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("specCounter", func() {
+	It("should count keys in a spec", func() {
+		counter := &specCounter{}
+		Expect(counter.next("a", "foo")).To(Equal(1))
+		Expect(counter.next("a", "foo")).To(Equal(2))
+		Expect(counter.next("a", "bar")).To(Equal(1))
+	})
+
+	It("should reset counts when the spec changes", func() {
+		counter := &specCounter{}
+		Expect(counter.next("a", "foo")).To(Equal(1))
+		Expect(counter.next("b", "foo")).To(Equal(1))
+		Expect(counter.next("a", "foo")).To(Equal(1))
+	})
+})
+
+var _ = Describe("numberSnapshot", func() {
+	var (
+		fs      *tempFs
+		storage *SuiteStorage
+	)
+
+	BeforeEach(func() {
+		fs = newTempFs()
+		storage = &SuiteStorage{
+			Path: filepath.Join(fs.path, "foo.golden"),
+			Name: "Foo",
+			Fs:   fs,
+		}
+		Expect(storage.Write([]byte("foo"))).To(Succeed())
+	})
+
+	AfterEach(func() {
+		fs.Teardown()
+	})
+
+	newNumberedMatcher := func(n int) *Matcher {
+		s := *storage
+		m := newMatcher("", "", []Option{
+			WithStorage(&s),
+			WithSerializer(&StringSerializer{}),
+			WithUpdateMode(UpdateNone),
+		})
+		numberSnapshot(m, n)
+
+		return m
+	}
+
+	It("should append the number to the key", func() {
+		Expect(newNumberedMatcher(2).Storage.(*SuiteStorage).Name).To(Equal("Foo 2"))
+	})
+
+	It("should fall back to the key without a number for the first snapshot", func() {
+		Expect(newNumberedMatcher(1).Match("foo")).To(BeTrue())
+		Expect(newNumberedMatcher(1).Match("bar")).To(BeFalse())
+	})
+
+	It("should prefer the numbered key", func() {
+		numbered := &SuiteStorage{Path: storage.Path, Name: "Foo 1", Fs: fs}
+		Expect(numbered.Write([]byte("bar"))).To(Succeed())
+		Expect(newNumberedMatcher(1).Match("bar")).To(BeTrue())
+	})
+
+	It("should not fall back for other snapshots", func() {
+		_, err := newNumberedMatcher(2).Match("foo")
+		Expect(err).To(MatchError(ErrSnapshotNotFound))
+	})
+})
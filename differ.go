@@ -1,12 +1,19 @@
 package goldga
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"os"
+	"reflect"
+	"sort"
 	"strings"
 
 	"github.com/andreyvit/diff"
 	aurora "github.com/logrusorgru/aurora/v3"
 	isatty "github.com/mattn/go-isatty"
+	yaml "gopkg.in/yaml.v2"
 )
 
 // nolint: gochecknoglobals
@@ -32,22 +39,247 @@ func (ColorDiffer) Diff(snapshot, received []byte) []byte {
 	}
 	lines = append(lines, diff.LineDiffAsLines(string(snapshot), string(received))...)
 
-	if colorSupported {
-		for i, line := range lines {
-			if len(line) == 0 {
-				continue
+	return []byte(strings.Join(colorizeLines(lines), "\n"))
+}
+
+// colorizeLines colors lines by their first character when the terminal
+// supports colors.
+func colorizeLines(lines []string) []string {
+	if !colorSupported {
+		return lines
+	}
+
+	for i, line := range lines {
+		if len(line) == 0 {
+			continue
+		}
+
+		switch line[0] {
+		case '+':
+			lines[i] = aurora.BrightGreen(line).String()
+		case '-':
+			lines[i] = aurora.BrightRed(line).String()
+		case '~':
+			lines[i] = aurora.BrightYellow(line).String()
+		default:
+			lines[i] = aurora.BrightBlack(line).String()
+		}
+	}
+
+	return lines
+}
+
+var _ Differ = (*StructuredDiffer)(nil)
+
+// StructuredDiffer parses JSON or YAML snapshots and reports changes by their
+// paths, e.g. `spec.containers[0].image: "a" → "b"`, so reordered keys or
+// indentation changes do not produce noisy diffs. When the content cannot be
+// parsed, or the only differences are formatting, Fallback is used.
+type StructuredDiffer struct {
+	// Format is either "json" or "yaml". When it is empty, the format is
+	// chosen by the serializer of the matcher, and JSON is tried before YAML
+	// for other serializers.
+	Format string
+
+	// Fallback defaults to ColorDiffer.
+	Fallback Differ
+}
+
+func (s *StructuredDiffer) Diff(snapshot, received []byte) []byte {
+	a, aErr := s.unmarshal(snapshot)
+	b, bErr := s.unmarshal(received)
+
+	var changes []string
+
+	if aErr == nil && bErr == nil {
+		changes = diffValues(nil, "", a, b)
+	}
+
+	if len(changes) == 0 {
+		fallback := s.Fallback
+
+		if fallback == nil {
+			fallback = &ColorDiffer{}
+		}
+
+		return fallback.Diff(snapshot, received)
+	}
+
+	lines := []string{
+		"- Snapshot",
+		"+ Received",
+		"",
+	}
+	lines = append(lines, changes...)
+
+	return []byte(strings.Join(colorizeLines(lines), "\n"))
+}
+
+func (s *StructuredDiffer) unmarshal(data []byte) (interface{}, error) {
+	var (
+		result interface{}
+		err    error
+	)
+
+	switch s.Format {
+	case "json":
+		result, err = unmarshalJSON(data)
+	case "yaml":
+		result, err = unmarshalYAML(data)
+	case "":
+		if result, err = unmarshalJSON(data); err != nil {
+			result, err = unmarshalYAML(data)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported format %q", s.Format)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	// Scalars are better displayed by line diffs.
+	switch result.(type) {
+	case map[string]interface{}, []interface{}:
+		return result, nil
+	}
+
+	return nil, errors.New("content is not a map or a slice")
+}
+
+func unmarshalJSON(data []byte) (interface{}, error) {
+	var result interface{}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	if err := dec.Decode(&result); err != nil {
+		return nil, fmt.Errorf("json decode error: %w", err)
+	}
+
+	return result, nil
+}
+
+func unmarshalYAML(data []byte) (interface{}, error) {
+	var result interface{}
+
+	if err := yaml.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("yaml decode error: %w", err)
+	}
+
+	return normalizeYAML(result), nil
+}
+
+// normalizeYAML converts maps decoded by yaml.v2 to map[string]interface{}.
+func normalizeYAML(input interface{}) interface{} {
+	switch input := input.(type) {
+	case map[interface{}]interface{}:
+		result := make(map[string]interface{}, len(input))
+
+		for k, v := range input {
+			result[fmt.Sprint(k)] = normalizeYAML(v)
+		}
+
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(input))
+
+		for i, v := range input {
+			result[i] = normalizeYAML(v)
+		}
+
+		return result
+	}
+
+	return input
+}
+
+func joinDiffPath(path, key string) string {
+	if strings.ContainsAny(key, ".[] \"") || key == "" {
+		return fmt.Sprintf("%s[%q]", path, key)
+	}
+
+	if path == "" {
+		return key
+	}
+
+	return path + "." + key
+}
+
+func formatDiffPath(path string) string {
+	if path == "" {
+		return "(root)"
+	}
+
+	return path
+}
+
+func formatDiffValue(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+
+	return string(data)
+}
+
+func diffValues(changes []string, path string, a, b interface{}) []string {
+	switch a := a.(type) {
+	case map[string]interface{}:
+		if b, ok := b.(map[string]interface{}); ok {
+			keys := make([]string, 0, len(a)+len(b))
+
+			for k := range a {
+				keys = append(keys, k)
 			}
 
-			switch line[0] {
-			case '+':
-				lines[i] = aurora.BrightGreen(line).String()
-			case '-':
-				lines[i] = aurora.BrightRed(line).String()
-			default:
-				lines[i] = aurora.BrightBlack(line).String()
+			for k := range b {
+				if _, ok := a[k]; !ok {
+					keys = append(keys, k)
+				}
 			}
+
+			sort.Strings(keys)
+
+			for _, k := range keys {
+				av, aok := a[k]
+				bv, bok := b[k]
+				p := joinDiffPath(path, k)
+
+				switch {
+				case !aok:
+					changes = append(changes, fmt.Sprintf("+ %s: %s", p, formatDiffValue(bv)))
+				case !bok:
+					changes = append(changes, fmt.Sprintf("- %s: %s", p, formatDiffValue(av)))
+				default:
+					changes = diffValues(changes, p, av, bv)
+				}
+			}
+
+			return changes
 		}
+	case []interface{}:
+		if b, ok := b.([]interface{}); ok {
+			for i := 0; i < len(a) || i < len(b); i++ {
+				p := fmt.Sprintf("%s[%d]", path, i)
+
+				switch {
+				case i >= len(a):
+					changes = append(changes, fmt.Sprintf("+ %s: %s", p, formatDiffValue(b[i])))
+				case i >= len(b):
+					changes = append(changes, fmt.Sprintf("- %s: %s", p, formatDiffValue(a[i])))
+				default:
+					changes = diffValues(changes, p, a[i], b[i])
+				}
+			}
+
+			return changes
+		}
+	}
+
+	if reflect.DeepEqual(a, b) {
+		return changes
 	}
 
-	return []byte(strings.Join(lines, "\n"))
+	return append(changes, fmt.Sprintf("~ %s: %s → %s", formatDiffPath(path), formatDiffValue(a), formatDiffValue(b)))
 }
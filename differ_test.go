@@ -0,0 +1,81 @@
+package goldga
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ColorDiffer", func() {
+	It("should show line diff", func() {
+		Expect(string((&ColorDiffer{}).Diff([]byte("a\nb"), []byte("a\nc")))).To(Equal(`- Snapshot
++ Received
+
+ a
+-b
++c`))
+	})
+})
+
+var _ = Describe("StructuredDiffer", func() {
+	var differ *StructuredDiffer
+
+	BeforeEach(func() {
+		differ = &StructuredDiffer{}
+	})
+
+	diff := func(snapshot, received string) string {
+		return string(differ.Diff([]byte(snapshot), []byte(received)))
+	}
+
+	It("should report changes by path", func() {
+		Expect(diff(
+			`{"spec":{"containers":[{"image":"a","name":"foo"}],"replicas":1,"paused":false},"z.z":1}`,
+			`{"spec":{"replicas":1,"containers":[{"name":"foo","image":"b"},{"name":"bar"}],"selector":{}},"z.z":1}`,
+		)).To(Equal(`- Snapshot
++ Received
+
+~ spec.containers[0].image: "a" → "b"
++ spec.containers[1]: {"name":"bar"}
+- spec.paused: false
++ spec.selector: {}`))
+	})
+
+	It("should quote keys with special characters", func() {
+		Expect(diff(`{"a.b":1}`, `{"a.b":2}`)).To(HaveSuffix(`~ ["a.b"]: 1 → 2`))
+	})
+
+	It("should parse YAML", func() {
+		Expect(diff("a:\n  b: 1\n", "a:\n  b: 2\n")).To(HaveSuffix(`~ a.b: 1 → 2`))
+	})
+
+	When("format is json", func() {
+		BeforeEach(func() {
+			differ.Format = "json"
+		})
+
+		It("should not parse YAML", func() {
+			Expect(diff("a: 1\n", "a: 2\n")).To(HaveSuffix("-a: 1\n+a: 2"))
+		})
+	})
+
+	It("should fall back to line diff when content is not structured", func() {
+		Expect(diff("(string) a", "(string) b")).To(HaveSuffix("-(string) a\n+(string) b"))
+	})
+
+	It("should fall back to line diff when only formatting changed", func() {
+		Expect(diff(`{"a":1,"b":2}`, `{"b":2,"a":1}`)).To(HaveSuffix(`-{"a":1,"b":2}` + "\n" + `+{"b":2,"a":1}`))
+	})
+
+	It("should use custom fallback differ", func() {
+		differ.Fallback = differFunc(func(snapshot, received []byte) []byte {
+			return []byte("fallback")
+		})
+		Expect(diff("a", "b")).To(Equal("fallback"))
+	})
+})
+
+type differFunc func(snapshot, received []byte) []byte
+
+func (f differFunc) Diff(snapshot, received []byte) []byte {
+	return f(snapshot, received)
+}
@@ -0,0 +1,155 @@
+package goldga
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/afero"
+)
+
+// nolint: gochecknoglobals
+var fileNameReplacer = regexp.MustCompile(`[^\w.-]+`)
+
+// Layout determines how snapshots are stored by default.
+type Layout string
+
+const (
+	// LayoutSuite stores snapshots of a test file in a single golden file at
+	// "testdata/<file>.golden". This is the default layout.
+	LayoutSuite Layout = "suite"
+
+	// LayoutDir stores each snapshot in its own file at
+	// "testdata/<file>/<spec name>.<ext>", which avoids merge conflicts when
+	// snapshots are added to the same test file in different branches.
+	LayoutDir Layout = "dir"
+)
+
+// ParseLayout parses a layout. An empty string means LayoutSuite.
+func ParseLayout(s string) (Layout, error) {
+	switch layout := Layout(s); layout {
+	case LayoutSuite, LayoutDir:
+		return layout, nil
+	case "":
+		return LayoutSuite, nil
+	}
+
+	return "", fmt.Errorf("invalid layout %q", s)
+}
+
+// getLayout returns the layout set by the GOLDGA_LAYOUT environment variable,
+// or fallback when it is not set.
+func getLayout(fallback Layout) Layout {
+	value := os.Getenv("GOLDGA_LAYOUT")
+
+	if value == "" && fallback != "" {
+		return fallback
+	}
+
+	layout, err := ParseLayout(value)
+	if err != nil {
+		panic(fmt.Errorf("GOLDGA_LAYOUT: %w", err))
+	}
+
+	return layout
+}
+
+// newStorage returns the storage of the given layout. path is the path of the
+// golden file of the suite layout.
+func newStorage(layout Layout, path, name string) Storage {
+	if layout == LayoutDir {
+		return &DirStorage{
+			Dir:  strings.TrimSuffix(path, GoldenFileExt),
+			Name: name,
+			Fs:   defaultFs,
+		}
+	}
+
+	return &SuiteStorage{
+		Path: path,
+		Name: name,
+		Fs:   defaultFs,
+	}
+}
+
+// getSerializerExt returns the file extension for snapshots serialized by the
+// serializer.
+func getSerializerExt(serializer Serializer) string {
+	switch serializer.(type) {
+	case *JSONSerializer:
+		return ".json"
+	case *YAMLSerializer:
+		return ".yaml"
+	case *TOMLSerializer:
+		return ".toml"
+	case *ImageSerializer:
+		return ".png"
+	}
+
+	return ".txt"
+}
+
+// sanitizeFileName replaces characters which are not safe in file names.
+func sanitizeFileName(name string) string {
+	name = strings.Trim(fileNameReplacer.ReplaceAllString(name, "_"), "_")
+
+	// Avoid empty names and "..", which refers to the parent directory.
+	if strings.Trim(name, ".") == "" {
+		return "_"
+	}
+
+	return name
+}
+
+var (
+	_ Storage              = (*DirStorage)(nil)
+	_ lineEndingNormalizer = (*DirStorage)(nil)
+)
+
+// DirStorage stores each snapshot in its own file. Name is sanitized to a file
+// name, and "/" in Name creates subdirectories, e.g. for subtests.
+type DirStorage struct {
+	Dir  string
+	Name string
+	Fs   afero.Fs
+
+	// Ext is the extension of the file. When it is empty, it is chosen by the
+	// serializer of the matcher, e.g. ".json" for JSONSerializer.
+	Ext string
+
+	// KeepLineEndings disables converting CRLF to LF on read and write.
+	KeepLineEndings bool
+}
+
+// Path returns the path of the snapshot file.
+func (s *DirStorage) Path() string {
+	segments := strings.Split(s.Name, "/")
+
+	for i, segment := range segments {
+		segments[i] = sanitizeFileName(segment)
+	}
+
+	return filepath.Join(s.Dir, filepath.Join(segments...)+s.Ext)
+}
+
+func (s *DirStorage) file() *SingleStorage {
+	return &SingleStorage{
+		Path:            s.Path(),
+		Fs:              s.Fs,
+		KeepLineEndings: s.KeepLineEndings,
+	}
+}
+
+func (s *DirStorage) Read() ([]byte, error) {
+	return s.file().Read()
+}
+
+func (s *DirStorage) Write(data []byte) error {
+	return s.file().Write(data)
+}
+
+func (s *DirStorage) normalizeLineEndings(data []byte) []byte {
+	return s.file().normalizeLineEndings(data)
+}
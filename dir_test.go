@@ -0,0 +1,100 @@
+package goldga
+
+import (
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/afero"
+)
+
+var _ = Describe("ParseLayout", func() {
+	DescribeTable("valid", func(input string, expected Layout) {
+		Expect(ParseLayout(input)).To(Equal(expected))
+	},
+		Entry("empty", "", LayoutSuite),
+		Entry("suite", "suite", LayoutSuite),
+		Entry("dir", "dir", LayoutDir),
+	)
+
+	It("should return error for invalid layouts", func() {
+		_, err := ParseLayout("foo")
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("DirStorage", func() {
+	var (
+		fs      *tempFs
+		storage *DirStorage
+	)
+
+	BeforeEach(func() {
+		fs = newTempFs()
+		storage = &DirStorage{
+			Dir:  filepath.Join(fs.path, "testdata", "foo"),
+			Name: "Foo should work",
+			Ext:  ".json",
+			Fs:   fs,
+		}
+	})
+
+	AfterEach(func() {
+		fs.Teardown()
+	})
+
+	DescribeTable("Path", func(name, expected string) {
+		storage.Name = name
+		Expect(storage.Path()).To(Equal(filepath.Join(storage.Dir, filepath.FromSlash(expected))))
+	},
+		Entry("spaces", "Foo should work", "Foo_should_work.json"),
+		Entry("special characters", `Foo "bar" (baz)`, "Foo_bar_baz.json"),
+		Entry("subtests", "TestFoo/bar baz", "TestFoo/bar_baz.json"),
+		Entry("parent directory", "../..", "_/_.json"),
+		Entry("empty", "", "_.json"),
+	)
+
+	It("should write and read the snapshot", func() {
+		Expect(storage.Write([]byte("foo"))).To(Succeed())
+		Expect(afero.ReadFile(fs, filepath.Join(storage.Dir, "Foo_should_work.json"))).To(Equal([]byte("foo")))
+		Expect(storage.Read()).To(Equal([]byte("foo")))
+	})
+
+	It("should return not found error when the file does not exist", func() {
+		_, err := storage.Read()
+		Expect(err).To(MatchError(afero.ErrFileNotFound))
+	})
+})
+
+var _ = Describe("WithLayout", func() {
+	newDirMatcher := func(options ...Option) *Matcher {
+		return newMatcher(filepath.Join("testdata", "foo.golden"), "Foo bar", append([]Option{WithLayout(LayoutDir)}, options...))
+	}
+
+	It("should use DirStorage", func() {
+		Expect(newDirMatcher().Storage).To(Equal(&DirStorage{
+			Dir:  filepath.Join("testdata", "foo"),
+			Name: "Foo bar",
+			Ext:  ".txt",
+			Fs:   defaultFs,
+		}))
+	})
+
+	It("should choose the extension by the serializer", func() {
+		Expect(newDirMatcher(WithSerializer(&JSONSerializer{})).Storage.(*DirStorage).Ext).To(Equal(".json"))
+		Expect(newDirMatcher(WithSerializer(&YAMLSerializer{})).Storage.(*DirStorage).Ext).To(Equal(".yaml"))
+		Expect(newDirMatcher(WithBinary()).Storage.(*DirStorage).Ext).To(Equal(".bin"))
+	})
+
+	It("should append the description to the file name", func() {
+		Expect(newDirMatcher(WithDescription("baz")).Storage.(*DirStorage).Path()).To(Equal(filepath.Join("testdata", "foo", "Foo_bar_baz.txt")))
+	})
+
+	It("should switch back to the suite layout", func() {
+		Expect(newDirMatcher(WithLayout(LayoutSuite)).Storage).To(Equal(&SuiteStorage{
+			Path: filepath.Join("testdata", "foo.golden"),
+			Name: "Foo bar",
+			Fs:   defaultFs,
+		}))
+	})
+})
@@ -0,0 +1,66 @@
+package goldga
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/onsi/ginkgo/v2/types"
+)
+
+// checkDuplicateSnapshots returns an error when different specs wrote the same
+// snapshot, e.g. because they have the same text or the same ID.
+func checkDuplicateSnapshots(report types.Report) error {
+	owners := map[snapshotRef]int{}
+	var messages []string
+
+	for i, spec := range report.SpecReports {
+		for _, entry := range spec.ReportEntries {
+			if entry.Name != snapshotReportEntryName {
+				continue
+			}
+
+			var ref snapshotRef
+
+			if err := decodeReportEntry(entry, &ref); err != nil {
+				return fmt.Errorf("failed to collect snapshots: %w", err)
+			}
+
+			owner, ok := owners[ref]
+
+			if !ok {
+				owners[ref] = i
+
+				continue
+			}
+
+			if owner == i {
+				continue
+			}
+
+			messages = append(messages, formatDuplicateSnapshot(ref, report.SpecReports[owner], spec))
+		}
+	}
+
+	if len(messages) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("goldga: found %d duplicate snapshot(s)\n%s\nUse WithKey or a \"%s<id>\" label to give them unique keys.",
+		len(messages), strings.Join(messages, ""), idLabelPrefix)
+}
+
+func formatDuplicateSnapshot(ref snapshotRef, specs ...types.SpecReport) string {
+	var b strings.Builder
+
+	if ref.Layout == LayoutDir {
+		fmt.Fprintf(&b, "  %s is written by:\n", ref.Path)
+	} else {
+		fmt.Fprintf(&b, "  %q in %s is written by:\n", ref.Name, ref.Path)
+	}
+
+	for _, spec := range specs {
+		fmt.Fprintf(&b, "    %s (%s)\n", spec.FullText(), spec.LeafNodeLocation)
+	}
+
+	return b.String()
+}
@@ -0,0 +1,74 @@
+package goldga
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	"github.com/onsi/ginkgo/v2/types"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("checkDuplicateSnapshots", func() {
+	newSpecReport := func(text string, line int, refs ...snapshotRef) types.SpecReport {
+		spec := types.SpecReport{
+			LeafNodeType:     types.NodeTypeIt,
+			LeafNodeText:     text,
+			LeafNodeLocation: types.CodeLocation{FileName: "foo_test.go", LineNumber: line},
+			State:            types.SpecStatePassed,
+		}
+
+		for _, ref := range refs {
+			spec.ReportEntries = append(spec.ReportEntries, types.ReportEntry{
+				Name:  snapshotReportEntryName,
+				Value: types.WrapEntryValue(ref),
+			})
+		}
+
+		return spec
+	}
+
+	foo := snapshotRef{Path: "testdata/foo.golden", Name: "Foo"}
+	bar := snapshotRef{Path: "testdata/foo.golden", Name: "Bar"}
+
+	It("should not return error when keys are unique", func() {
+		Expect(checkDuplicateSnapshots(types.Report{
+			SpecReports: types.SpecReports{
+				newSpecReport("Foo", 1, foo),
+				newSpecReport("Bar", 2, bar),
+			},
+		})).To(Succeed())
+	})
+
+	It("should allow a spec to use a snapshot multiple times", func() {
+		Expect(checkDuplicateSnapshots(types.Report{
+			SpecReports: types.SpecReports{
+				newSpecReport("Foo", 1, foo, foo),
+			},
+		})).To(Succeed())
+	})
+
+	It("should return error when specs use the same key", func() {
+		err := checkDuplicateSnapshots(types.Report{
+			SpecReports: types.SpecReports{
+				newSpecReport("Foo", 1, foo),
+				newSpecReport("Bar", 2, bar),
+				newSpecReport("Foo", 3, foo),
+			},
+		})
+		Expect(err).To(MatchError(`goldga: found 1 duplicate snapshot(s)
+  "Foo" in testdata/foo.golden is written by:
+    Foo (foo_test.go:1)
+    Foo (foo_test.go:3)
+
+Use WithKey or a "goldga:id=<id>" label to give them unique keys.`))
+	})
+
+	It("should return error when specs use the same file in the dir layout", func() {
+		ref := snapshotRef{Path: "testdata/foo/Foo.txt", Layout: LayoutDir}
+		err := checkDuplicateSnapshots(types.Report{
+			SpecReports: types.SpecReports{
+				newSpecReport("Foo", 1, ref),
+				newSpecReport("Foo", 2, ref),
+			},
+		})
+		Expect(err).To(MatchError(ContainSubstring("  testdata/foo/Foo.txt is written by:\n")))
+	})
+})
@@ -0,0 +1,88 @@
+package goldga
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/afero"
+)
+
+const (
+	testdataDirName   = "testdata"
+	gitattributesName = ".gitattributes"
+	gitattributesHint = `# Generated by goldga. Golden files must be checked out with LF line endings,
+# otherwise snapshots do not match on Windows when core.autocrlf is enabled.
+*.golden text eol=lf
+*.golden.new text eol=lf
+*.png binary
+`
+)
+
+// lineEndingNormalizer is implemented by storages which normalize line
+// endings, so the actual content must be normalized in the same way before it
+// is compared with the snapshot.
+type lineEndingNormalizer interface {
+	normalizeLineEndings(data []byte) []byte
+}
+
+var (
+	_ lineEndingNormalizer = (*SingleStorage)(nil)
+	_ lineEndingNormalizer = (*SuiteStorage)(nil)
+)
+
+// normalizeLineEndings converts CRLF to LF.
+func normalizeLineEndings(data []byte) []byte {
+	if !bytes.Contains(data, []byte("\r\n")) {
+		return data
+	}
+
+	return bytes.ReplaceAll(data, []byte("\r\n"), []byte("\n"))
+}
+
+// mkdirAll creates the directory and its parents. When a testdata directory is
+// created, a .gitattributes file is added to it to keep LF line endings.
+func mkdirAll(fs afero.Fs, dir string) error {
+	testdata := findTestdataDir(dir)
+	created := false
+
+	if testdata != "" {
+		exists, err := afero.DirExists(fs, testdata)
+		if err != nil {
+			return fmt.Errorf("failed to check directory exist: %w", err)
+		}
+
+		created = !exists
+	}
+
+	if err := fs.MkdirAll(dir, os.ModePerm); err != nil {
+		return fmt.Errorf("failed to create directories: %w", err)
+	}
+
+	if !created {
+		return nil
+	}
+
+	path := filepath.Join(testdata, gitattributesName)
+
+	if err := afero.WriteFile(fs, path, []byte(gitattributesHint), 0o644); err != nil && !errors.Is(err, os.ErrExist) {
+		return fmt.Errorf("failed to write %s: %w", gitattributesName, err)
+	}
+
+	return nil
+}
+
+// findTestdataDir returns the closest testdata directory in the path.
+func findTestdataDir(dir string) string {
+	for dir = filepath.Clean(dir); ; dir = filepath.Dir(dir) {
+		if filepath.Base(dir) == testdataDirName {
+			return dir
+		}
+
+		if parent := filepath.Dir(dir); parent == dir {
+			return ""
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package goldga
+
+import (
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/afero"
+)
+
+var _ = Describe("normalizeLineEndings", func() {
+	It("should convert CRLF to LF", func() {
+		Expect(normalizeLineEndings([]byte("a\r\nb\rc\n"))).To(Equal([]byte("a\nb\rc\n")))
+	})
+})
+
+var _ = Describe("mkdirAll", func() {
+	var fs *tempFs
+
+	BeforeEach(func() {
+		fs = newTempFs()
+	})
+
+	AfterEach(func() {
+		fs.Teardown()
+	})
+
+	It("should write .gitattributes when testdata directory is created", func() {
+		Expect(mkdirAll(fs, filepath.Join(fs.path, "testdata", "foo"))).To(Succeed())
+		Expect(afero.ReadFile(fs, filepath.Join(fs.path, "testdata", ".gitattributes"))).To(ContainSubstring("*.golden text eol=lf"))
+	})
+
+	It("should not write .gitattributes when testdata directory exists", func() {
+		Expect(fs.Mkdir(filepath.Join(fs.path, "testdata"), 0o755)).To(Succeed())
+		Expect(mkdirAll(fs, filepath.Join(fs.path, "testdata", "foo"))).To(Succeed())
+		Expect(afero.Exists(fs, filepath.Join(fs.path, "testdata", ".gitattributes"))).To(BeFalse())
+	})
+
+	It("should not write .gitattributes outside testdata directory", func() {
+		Expect(mkdirAll(fs, filepath.Join(fs.path, "foo"))).To(Succeed())
+		Expect(afero.Exists(fs, filepath.Join(fs.path, "foo", ".gitattributes"))).To(BeFalse())
+		Expect(afero.DirExists(fs, filepath.Join(fs.path, "foo"))).To(BeTrue())
+	})
+})
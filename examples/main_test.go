@@ -1,6 +1,8 @@
 package main
 
 import (
+	"image"
+	"image/color"
 	"testing"
 
 	. "github.com/onsi/ginkgo/v2"
@@ -32,6 +34,25 @@ var _ = Describe("Examples", func() {
 		}).To(goldga.Match())
 	})
 
+	It("inline", func() {
+		Expect(map[string]int{"a": 1}).To(goldga.MatchInline(`(map[string]int) (len=1) {
+ (string) (len=1) "a": (int) 1
+}
+`))
+	})
+
+	It("image", func() {
+		img := image.NewRGBA(image.Rect(0, 0, 16, 16))
+
+		for y := 0; y < 16; y++ {
+			for x := 0; x < 16; x++ {
+				img.Set(x, y, color.RGBA{R: uint8(x * 16), G: uint8(y * 16), B: 128, A: 255})
+			}
+		}
+
+		Expect(img).To(goldga.MatchImage(goldga.WithThreshold(2)))
+	})
+
 	It("multiple gold files in the same test", func() {
 		Expect("foo").To(goldga.Match(goldga.WithDescription("first gold file")))
 		Expect("bar").To(goldga.Match(goldga.WithDescription("second gold file")))
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"log"
+	"os"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/tommy351/goldga"
+)
+
+func TestMain(m *testing.M) {
+	code := m.Run()
+	report := goldga.Flush
+
+	if code == 0 {
+		report = func() error { return goldga.ReportObsoleteT(os.Stdout) }
+	}
+
+	if err := report(); err != nil {
+		log.Fatal(err)
+	}
+
+	os.Exit(code)
+}
+
+func TestMatchT(t *testing.T) {
+	g := NewWithT(t)
+	g.Expect("abc").To(goldga.MatchT(t))
+
+	t.Run("subtest", func(t *testing.T) {
+		g := NewWithT(t)
+		g.Expect([]int{1, 2, 3}).To(goldga.MatchT(t))
+	})
+}
@@ -5,16 +5,29 @@ import (
 	"strings"
 
 	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/ginkgo/v2/types"
 )
 
-func getGinkgoPath() string {
-	spec := ginkgo.CurrentSpecReport()
-	path := spec.FileName()
+// idLabelPrefix is the prefix of Ginkgo labels which set the key of snapshots,
+// e.g. Label("goldga:id=users").
+const idLabelPrefix = "goldga:id="
+
+func getGinkgoFile() string {
+	path := ginkgo.CurrentSpecReport().FileName()
 
 	if path == "" {
 		panic("current file name is empty")
 	}
 
+	return path
+}
+
+func getGinkgoPath() string {
+	return resolveGoldenPath(getConfig().getPathResolver(), getGinkgoFile())
+}
+
+// getGoldenPath returns the path of the golden file for the given test file.
+func getGoldenPath(path string) string {
 	name := filepath.Base(path)
 
 	if ext := filepath.Ext(name); ext != "" {
@@ -26,8 +39,7 @@ func getGinkgoPath() string {
 }
 
 func getGinkgoTestName() string {
-	spec := ginkgo.CurrentSpecReport()
-	testName := spec.FullText()
+	testName := getSpecKey(ginkgo.CurrentSpecReport())
 
 	if testName == "" {
 		panic("current test name is empty")
@@ -35,3 +47,43 @@ func getGinkgoTestName() string {
 
 	return testName
 }
+
+// getSpecKey returns the full text of the spec, in which the text of each
+// container and the spec itself is replaced by its ID if it has a
+// "goldga:id=<id>" label.
+func getSpecKey(spec types.SpecReport) string {
+	texts := make([]string, 0, len(spec.ContainerHierarchyTexts)+1)
+
+	for i, text := range spec.ContainerHierarchyTexts {
+		var labels []string
+
+		if i < len(spec.ContainerHierarchyLabels) {
+			labels = spec.ContainerHierarchyLabels[i]
+		}
+
+		texts = append(texts, getLabelID(labels, text))
+	}
+
+	if text := getLabelID(spec.LeafNodeLabels, spec.LeafNodeText); text != "" {
+		texts = append(texts, text)
+	}
+
+	return strings.Join(texts, " ")
+}
+
+func getLabelID(labels []string, text string) string {
+	for _, label := range labels {
+		if strings.HasPrefix(label, idLabelPrefix) {
+			return strings.TrimPrefix(label, idLabelPrefix)
+		}
+	}
+
+	return text
+}
+
+// getGinkgoLocation returns the location of the current spec.
+func getGinkgoLocation() (string, int) {
+	location := ginkgo.CurrentSpecReport().LeafNodeLocation
+
+	return location.FileName, location.LineNumber
+}
@@ -0,0 +1,53 @@
+package goldga
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/afero"
+)
+
+// FindGoldenFiles returns the sorted paths of golden files created by
+// SuiteStorage in the given directory and its subdirectories. When root is a
+// file, it is returned as is.
+func FindGoldenFiles(fs afero.Fs, root string) ([]string, error) {
+	var result []string
+
+	err := walkFiles(fs, root, func(path string) error {
+		if path == root || strings.HasSuffix(path, GoldenFileExt) {
+			result = append(result, path)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find golden files: %w", err)
+	}
+
+	sort.Strings(result)
+
+	return result, nil
+}
+
+// walkFiles calls fn for every file in root and its subdirectories. Hidden
+// directories, e.g. .git, are skipped.
+func walkFiles(fs afero.Fs, root string, fn func(path string) error) error {
+	return afero.Walk(fs, root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() {
+			if path != root && strings.HasPrefix(info.Name(), ".") {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		return fn(path)
+	})
+}
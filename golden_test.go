@@ -0,0 +1,45 @@
+package goldga
+
+import (
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/afero"
+)
+
+var _ = Describe("FindGoldenFiles", func() {
+	var fs *tempFs
+
+	BeforeEach(func() {
+		fs = newTempFs()
+
+		for _, path := range []string{
+			"b/testdata/b.golden",
+			"a/testdata/a.golden",
+			"a/testdata/a.golden.new",
+			"a/testdata/a.png",
+			".git/c.golden",
+		} {
+			path = filepath.Join(fs.path, path)
+			Expect(fs.MkdirAll(filepath.Dir(path), 0o755)).To(Succeed())
+			Expect(afero.WriteFile(fs, path, nil, 0o644)).To(Succeed())
+		}
+	})
+
+	AfterEach(func() {
+		fs.Teardown()
+	})
+
+	It("should return golden files", func() {
+		Expect(FindGoldenFiles(fs, fs.path)).To(Equal([]string{
+			filepath.Join(fs.path, "a", "testdata", "a.golden"),
+			filepath.Join(fs.path, "b", "testdata", "b.golden"),
+		}))
+	})
+
+	It("should return the file when root is a file", func() {
+		path := filepath.Join(fs.path, "a", "testdata", "a.png")
+		Expect(FindGoldenFiles(fs, path)).To(Equal([]string{path}))
+	})
+})
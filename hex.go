@@ -0,0 +1,206 @@
+package goldga
+
+import (
+	"fmt"
+	"strings"
+
+	aurora "github.com/logrusorgru/aurora/v3"
+)
+
+const hexRowSize = 16
+
+var _ Differ = (*HexDiffer)(nil)
+
+// HexDiffer shows binary snapshots as xxd-style hexdump rows. Only rows which
+// differ are printed, and changed bytes are highlighted. When the terminal
+// does not support colors, changed bytes are marked with "^^" below the row.
+type HexDiffer struct {
+	// Context is the number of unchanged rows printed around changed rows.
+	Context int
+}
+
+func (h *HexDiffer) Diff(snapshot, received []byte) []byte {
+	lines := []string{
+		fmt.Sprintf("- Snapshot (%d bytes)", len(snapshot)),
+		fmt.Sprintf("+ Received (%d bytes)", len(received)),
+		"",
+	}
+
+	offset := firstDiffOffset(snapshot, received)
+	if offset < 0 {
+		return []byte(strings.Join(append(lines, "no differences"), "\n"))
+	}
+
+	lines = append(lines, fmt.Sprintf("first difference at offset 0x%08x (%d)", offset, offset), "")
+
+	size := len(snapshot)
+	if len(received) > size {
+		size = len(received)
+	}
+
+	rows := (size + hexRowSize - 1) / hexRowSize
+	changed := make([]bool, rows)
+
+	for i := range changed {
+		a, b := hexRow(snapshot, i), hexRow(received, i)
+		changed[i] = firstDiffOffset(a, b) >= 0
+	}
+
+	skipped := false
+
+	for i := 0; i < rows; i++ {
+		if !isNearChangedRow(changed, i, h.Context) {
+			skipped = true
+
+			continue
+		}
+
+		if skipped {
+			lines = append(lines, "  ...")
+			skipped = false
+		}
+
+		a, b := hexRow(snapshot, i), hexRow(received, i)
+
+		if !changed[i] {
+			lines = append(lines, "  "+formatHexRow(i*hexRowSize, a, a, nil))
+
+			continue
+		}
+
+		if a != nil {
+			lines = append(lines, formatHexDiffRow('-', i*hexRowSize, a, b)...)
+		}
+
+		if b != nil {
+			lines = append(lines, formatHexDiffRow('+', i*hexRowSize, b, a)...)
+		}
+	}
+
+	if skipped {
+		lines = append(lines, "  ...")
+	}
+
+	return []byte(strings.Join(lines, "\n"))
+}
+
+// firstDiffOffset returns the offset of the first different byte, or -1 when
+// both are equal.
+func firstDiffOffset(a, b []byte) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			return i
+		}
+	}
+
+	if len(a) != len(b) {
+		if len(a) < len(b) {
+			return len(a)
+		}
+
+		return len(b)
+	}
+
+	return -1
+}
+
+func hexRow(data []byte, row int) []byte {
+	start := row * hexRowSize
+
+	if start >= len(data) {
+		return nil
+	}
+
+	end := start + hexRowSize
+
+	if end > len(data) {
+		end = len(data)
+	}
+
+	return data[start:end]
+}
+
+func isNearChangedRow(changed []bool, row, context int) bool {
+	for i := row - context; i <= row+context; i++ {
+		if i >= 0 && i < len(changed) && changed[i] {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isHexByteChanged(row, other []byte, i int) bool {
+	return i >= len(other) || row[i] != other[i]
+}
+
+func formatHexDiffRow(sign byte, offset int, row, other []byte) []string {
+	highlight := func(s string) string {
+		if !colorSupported {
+			return s
+		}
+
+		if sign == '-' {
+			return aurora.Bold(aurora.BgRed(s)).String()
+		}
+
+		return aurora.Bold(aurora.BgGreen(s)).String()
+	}
+
+	line := string(sign) + " " + formatHexRow(offset, row, other, highlight)
+
+	if colorSupported {
+		return []string{line}
+	}
+
+	// The hex of the first byte starts after "- 00000000: ".
+	marker := []byte(strings.Repeat(" ", 12+hexRowSize*5/2))
+
+	for i := range row {
+		if isHexByteChanged(row, other, i) {
+			pos := 12 + i*2 + i/2
+			marker[pos], marker[pos+1] = '^', '^'
+		}
+	}
+
+	if m := strings.TrimRight(string(marker), " "); m != "" {
+		return []string{line, m}
+	}
+
+	return []string{line}
+}
+
+// formatHexRow formats a row like xxd, e.g.
+// "00000010: 6865 6c6c 6f0a            hello.". Bytes which differ from other
+// are passed to highlight when it is not nil.
+func formatHexRow(offset int, row, other []byte, highlight func(string) string) string {
+	var hex, text strings.Builder
+
+	for i := 0; i < hexRowSize; i++ {
+		if i%2 == 0 {
+			hex.WriteByte(' ')
+		}
+
+		if i >= len(row) {
+			hex.WriteString("  ")
+
+			continue
+		}
+
+		h := fmt.Sprintf("%02x", row[i])
+		c := "."
+
+		if row[i] >= 0x20 && row[i] < 0x7f {
+			c = string(row[i])
+		}
+
+		if highlight != nil && isHexByteChanged(row, other, i) {
+			h, c = highlight(h), highlight(c)
+		}
+
+		hex.WriteString(h)
+		text.WriteString(c)
+	}
+
+	return fmt.Sprintf("%08x:%s  %s", offset, hex.String(), text.String())
+}
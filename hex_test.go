@@ -0,0 +1,67 @@
+package goldga
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("HexDiffer", func() {
+	var differ *HexDiffer
+
+	BeforeEach(func() {
+		differ = &HexDiffer{}
+	})
+
+	diff := func(snapshot, received string) string {
+		return string(differ.Diff([]byte(snapshot), []byte(received)))
+	}
+
+	It("should show changed rows", func() {
+		Expect(diff(
+			"0123456789abcdef0123456789abcdef\x00\x01",
+			"0123456789abcdef0123456789aBcdef\x00\x02",
+		)).To(Equal(`- Snapshot (34 bytes)
++ Received (34 bytes)
+
+first difference at offset 0x0000001b (27)
+
+  ...
+- 00000010: 3031 3233 3435 3637 3839 6162 6364 6566  0123456789abcdef
+                                       ^^
++ 00000010: 3031 3233 3435 3637 3839 6142 6364 6566  0123456789aBcdef
+                                       ^^
+- 00000020: 0001                                     ..
+              ^^
++ 00000020: 0002                                     ..
+              ^^`))
+	})
+
+	It("should show bytes which only exist on one side", func() {
+		Expect(diff("abc", "abcde")).To(Equal(`- Snapshot (3 bytes)
++ Received (5 bytes)
+
+first difference at offset 0x00000003 (3)
+
+- 00000000: 6162 63                                  abc
++ 00000000: 6162 6364 65                             abcde
+                   ^^ ^^`))
+	})
+
+	It("should show rows which only exist on one side", func() {
+		Expect(diff("0123456789abcdef", "0123456789abcdefg")).To(HaveSuffix(`  ...
++ 00000010: 67                                       g
+            ^^`))
+	})
+
+	When("context is set", func() {
+		BeforeEach(func() {
+			differ.Context = 1
+		})
+
+		It("should show unchanged rows around changes", func() {
+			Expect(diff("0123456789abcdefx", "0123456789abcdefy")).To(ContainSubstring(`
+  00000000: 3031 3233 3435 3637 3839 6162 6364 6566  0123456789abcdef
+- 00000010: 78`))
+		})
+	})
+})
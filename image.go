@@ -0,0 +1,361 @@
+package goldga
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/jpeg" // Register the JPEG decoder for ImageSerializer.
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/onsi/gomega/types"
+	"github.com/spf13/afero"
+)
+
+const (
+	imageFileExt     = ".png"
+	imageDiffFileExt = ".diff.png"
+)
+
+type ImageOption func(*ImageMatcher)
+
+// WithThreshold sets the max difference of each color channel (0-255) for two
+// pixels to be considered equal.
+func WithThreshold(threshold uint8) ImageOption {
+	return func(matcher *ImageMatcher) {
+		matcher.Threshold = threshold
+	}
+}
+
+// WithMaxDiffRatio sets the max ratio (0-1) of pixels which may differ.
+func WithMaxDiffRatio(ratio float64) ImageOption {
+	return func(matcher *ImageMatcher) {
+		matcher.MaxDiffRatio = ratio
+	}
+}
+
+// WithMatcherOptions applies options of Match, e.g. WithUpdateMode, to the
+// image matcher.
+func WithMatcherOptions(options ...Option) ImageOption {
+	return func(matcher *ImageMatcher) {
+		for _, option := range options {
+			option(matcher.Matcher)
+		}
+	}
+}
+
+// MatchImage matches an image.Image or encoded PNG/JPEG bytes with a golden
+// PNG file at "testdata/<test file>/<spec name>.png". When images do not match,
+// a diff image is written next to the golden file.
+func MatchImage(options ...ImageOption) *ImageMatcher {
+	return newImageMatcher(getGinkgoFile(), getGinkgoTestName(), options)
+}
+
+// getImagePath returns the path of the golden image in the directory named
+// after the golden file.
+func getImagePath(goldenPath, name string) string {
+	return filepath.Join(
+		strings.TrimSuffix(goldenPath, GoldenFileExt),
+		sanitizeFileName(name)+imageFileExt,
+	)
+}
+
+func newImageMatcher(testFile, name string, options []ImageOption) *ImageMatcher {
+	path := getImagePath(resolveGoldenPath(getConfig().getPathResolver(), testFile), name)
+	m := &ImageMatcher{
+		Matcher: newMatcher(path, "", nil),
+	}
+	m.Serializer = &ImageSerializer{}
+	m.Transformer = DefaultTransformer
+	m.Scrubbers = nil
+	m.Storage = &SingleStorage{Path: path, Fs: defaultFs}
+	m.Differ = nil
+	m.Comparator = m
+
+	for _, option := range options {
+		option(m)
+	}
+
+	// WithPath moves the golden image like golden files of other matchers.
+	if s, ok := m.Storage.(*SingleStorage); ok && m.pathResolver != nil {
+		s.Path = getImagePath(resolveGoldenPath(m.pathResolver, testFile), name)
+	}
+
+	if m.Differ == nil {
+		differ := &ImageDiffer{Threshold: m.Threshold}
+
+		if s, ok := m.Storage.(*SingleStorage); ok {
+			differ.Path = strings.TrimSuffix(s.Path, imageFileExt) + imageDiffFileExt
+			differ.Fs = s.Fs
+		}
+
+		m.Differ = differ
+	}
+
+	return m
+}
+
+var _ types.GomegaMatcher = (*ImageMatcher)(nil)
+
+// ImageMatcher compares images pixel by pixel instead of byte by byte.
+type ImageMatcher struct {
+	*Matcher
+
+	// Threshold is the max difference of each color channel (0-255) for two
+	// pixels to be considered equal.
+	Threshold uint8
+
+	// MaxDiffRatio is the max ratio (0-1) of pixels which may differ.
+	MaxDiffRatio float64
+}
+
+func (m *ImageMatcher) Match(actual interface{}) (bool, error) {
+	success, err := m.Matcher.Match(actual)
+
+	// Remove the diff image of the previous failure.
+	if d, ok := m.Differ.(*ImageDiffer); ok && success && d.Path != "" && d.Fs != nil {
+		if err := d.Fs.Remove(d.Path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return false, fmt.Errorf("failed to remove diff image: %w", err)
+		}
+	}
+
+	return success, err
+}
+
+var _ Comparator = (*ImageMatcher)(nil)
+
+// Compare decodes images and compares them pixel by pixel.
+func (m *ImageMatcher) Compare(snapshot, received []byte) (bool, error) {
+	result, err := compareImages(snapshot, received, m.Threshold)
+	if err != nil {
+		return false, err
+	}
+
+	return !result.sizeChanged() && result.ratio() <= m.MaxDiffRatio, nil
+}
+
+var _ Serializer = (*ImageSerializer)(nil)
+
+// ImageSerializer encodes images as PNG. Encoded images, as byte slices or
+// readers, are decoded and encoded as PNG again, so the snapshot does not
+// depend on the original encoder.
+type ImageSerializer struct{}
+
+func (ImageSerializer) Serialize(w io.Writer, input interface{}) error {
+	var img image.Image
+
+	switch input := input.(type) {
+	case image.Image:
+		img = input
+	case []byte:
+		return (ImageSerializer{}).Serialize(w, bytes.NewReader(input))
+	case io.Reader:
+		decoded, _, err := image.Decode(input)
+		if err != nil {
+			return fmt.Errorf("image decode error: %w", err)
+		}
+
+		img = decoded
+	default:
+		return fmt.Errorf("unsupported image type %T", input)
+	}
+
+	if err := png.Encode(w, img); err != nil {
+		return fmt.Errorf("png encode error: %w", err)
+	}
+
+	return nil
+}
+
+var _ Differ = (*ImageDiffer)(nil)
+
+// ImageDiffer describes how many pixels differ. When Path is set, it also
+// writes a diff image which contains the snapshot, the changed pixels in red,
+// and the received image side by side.
+type ImageDiffer struct {
+	Threshold uint8
+	Path      string
+	Fs        afero.Fs
+}
+
+func (d *ImageDiffer) Diff(snapshot, received []byte) []byte {
+	result, err := compareImages(snapshot, received, d.Threshold)
+	if err != nil {
+		return []byte(err.Error())
+	}
+
+	lines := []string{
+		fmt.Sprintf("%d of %d pixels (%.2f%%) differ", result.changed, result.total, result.ratio()*100),
+	}
+
+	if result.sizeChanged() {
+		lines = append(lines, fmt.Sprintf("size changed: %s → %s", formatImageSize(result.a), formatImageSize(result.b)))
+	}
+
+	if d.Path != "" && d.Fs != nil {
+		if err := d.writeDiffImage(result); err != nil {
+			lines = append(lines, fmt.Sprintf("failed to write diff image: %v", err))
+		} else {
+			lines = append(lines, "diff image: "+d.Path)
+		}
+	}
+
+	return []byte(strings.Join(lines, "\n"))
+}
+
+func (d *ImageDiffer) writeDiffImage(result *imageDiff) error {
+	var buf bytes.Buffer
+
+	if err := png.Encode(&buf, result.sideBySide()); err != nil {
+		return fmt.Errorf("png encode error: %w", err)
+	}
+
+	return (&SingleStorage{Path: d.Path, Fs: d.Fs}).Write(buf.Bytes())
+}
+
+func formatImageSize(img image.Image) string {
+	size := img.Bounds().Size()
+
+	return fmt.Sprintf("%dx%d", size.X, size.Y)
+}
+
+type imageDiff struct {
+	a, b    image.Image
+	diff    *image.NRGBA
+	changed int
+	total   int
+}
+
+func (d *imageDiff) sizeChanged() bool {
+	return d.a.Bounds().Size() != d.b.Bounds().Size()
+}
+
+func (d *imageDiff) ratio() float64 {
+	if d.total == 0 {
+		return 0
+	}
+
+	return float64(d.changed) / float64(d.total)
+}
+
+// sideBySide returns an image which contains the snapshot, the diff and the
+// received image from left to right.
+func (d *imageDiff) sideBySide() image.Image {
+	images := []image.Image{d.a, d.diff, d.b}
+	width, height := 0, 0
+
+	for _, img := range images {
+		size := img.Bounds().Size()
+		width += size.X
+
+		if size.Y > height {
+			height = size.Y
+		}
+	}
+
+	result := image.NewNRGBA(image.Rect(0, 0, width, height))
+	x := 0
+
+	for _, img := range images {
+		bounds := img.Bounds()
+		draw.Draw(result, bounds.Sub(bounds.Min).Add(image.Pt(x, 0)), img, bounds.Min, draw.Src)
+		x += bounds.Dx()
+	}
+
+	return result
+}
+
+func decodeImage(data []byte) (image.Image, error) {
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("image decode error: %w", err)
+	}
+
+	return img, nil
+}
+
+// compareImages compares images pixel by pixel. Pixels which only exist in one
+// of the images are counted as changed.
+func compareImages(a, b []byte, threshold uint8) (*imageDiff, error) {
+	imgA, err := decodeImage(a)
+	if err != nil {
+		return nil, fmt.Errorf("snapshot: %w", err)
+	}
+
+	imgB, err := decodeImage(b)
+	if err != nil {
+		return nil, fmt.Errorf("received: %w", err)
+	}
+
+	sizeA, sizeB := imgA.Bounds().Size(), imgB.Bounds().Size()
+	width, height := sizeA.X, sizeA.Y
+
+	if sizeB.X > width {
+		width = sizeB.X
+	}
+
+	if sizeB.Y > height {
+		height = sizeB.Y
+	}
+
+	result := &imageDiff{
+		a:     imgA,
+		b:     imgB,
+		diff:  image.NewNRGBA(image.Rect(0, 0, width, height)),
+		total: width * height,
+	}
+
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			pt := image.Pt(x, y)
+			inA := pt.In(image.Rectangle{Max: sizeA})
+			inB := pt.In(image.Rectangle{Max: sizeB})
+
+			var ca, cb color.NRGBA
+
+			if inA {
+				ca = color.NRGBAModel.Convert(imgA.At(imgA.Bounds().Min.X+x, imgA.Bounds().Min.Y+y)).(color.NRGBA)
+			}
+
+			if inB {
+				cb = color.NRGBAModel.Convert(imgB.At(imgB.Bounds().Min.X+x, imgB.Bounds().Min.Y+y)).(color.NRGBA)
+			}
+
+			if !inA || !inB || !isColorSimilar(ca, cb, threshold) {
+				result.changed++
+				result.diff.SetNRGBA(x, y, color.NRGBA{R: 0xff, A: 0xff})
+
+				continue
+			}
+
+			// Unchanged pixels are faded, so changed pixels stand out.
+			gray := color.GrayModel.Convert(ca).(color.Gray).Y
+			gray = 0xff - (0xff-gray)/4
+			result.diff.SetNRGBA(x, y, color.NRGBA{R: gray, G: gray, B: gray, A: 0xff})
+		}
+	}
+
+	return result, nil
+}
+
+func isColorSimilar(a, b color.NRGBA, threshold uint8) bool {
+	for _, pair := range [][2]uint8{{a.R, b.R}, {a.G, b.G}, {a.B, b.B}, {a.A, b.A}} {
+		diff := int(pair[0]) - int(pair[1])
+
+		if diff < 0 {
+			diff = -diff
+		}
+
+		if diff > int(threshold) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,203 @@
+package goldga
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/afero"
+)
+
+var _ = Describe("ImageMatcher", func() {
+	var (
+		fs      *tempFs
+		path    string
+		options []ImageOption
+	)
+
+	newImage := func(pixels ...uint8) *image.Gray {
+		img := image.NewGray(image.Rect(0, 0, 2, len(pixels)/2))
+		copy(img.Pix, pixels)
+
+		return img
+	}
+
+	encode := func(img image.Image) []byte {
+		var buf bytes.Buffer
+		Expect(png.Encode(&buf, img)).To(Succeed())
+
+		return buf.Bytes()
+	}
+
+	match := func(img image.Image) (*ImageMatcher, bool) {
+		matcher := newImageMatcher("foo_test.go", "bar", append([]ImageOption{
+			WithMatcherOptions(
+				WithStorage(&SingleStorage{Path: path, Fs: fs}),
+				WithUpdateMode(UpdateNew),
+				WithCI(false),
+				WithReview(false),
+			),
+		}, options...))
+		success, err := matcher.Match(img)
+		Expect(err).NotTo(HaveOccurred())
+
+		return matcher, success
+	}
+
+	BeforeEach(func() {
+		fs = newTempFs()
+		path = filepath.Join(fs.path, "foo", "bar.png")
+		options = nil
+	})
+
+	AfterEach(func() {
+		fs.Teardown()
+	})
+
+	It("should use the spec name as the file name", func() {
+		matcher := MatchImage()
+		Expect(matcher.Storage).To(Equal(&SingleStorage{
+			Path: filepath.Join("testdata", "image", "ImageMatcher_should_use_the_spec_name_as_the_file_name.png"),
+			Fs:   defaultFs,
+		}))
+		Expect(matcher.Differ.(*ImageDiffer).Path).To(HaveSuffix("ImageMatcher_should_use_the_spec_name_as_the_file_name.diff.png"))
+	})
+
+	It("should move the golden image with WithPath", func() {
+		matcher := MatchImage(WithMatcherOptions(WithPath(&TemplatePathResolver{Template: "__snapshots__/{{.File}}.golden"})))
+		Expect(matcher.Storage).To(Equal(&SingleStorage{
+			Path: filepath.Join("__snapshots__", "image", "ImageMatcher_should_move_the_golden_image_with_WithPath.png"),
+			Fs:   defaultFs,
+		}))
+		Expect(matcher.Differ.(*ImageDiffer).Path).To(Equal(filepath.Join("__snapshots__", "image", "ImageMatcher_should_move_the_golden_image_with_WithPath.diff.png")))
+	})
+
+	It("should write the golden image without the executable bit", func() {
+		_, success := match(newImage(0, 1, 2, 3))
+		Expect(success).To(BeTrue())
+
+		info, err := fs.Stat(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(info.Mode().Perm() & 0o111).To(BeZero())
+	})
+
+	When("the snapshot does not exist", func() {
+		It("should write a PNG file", func() {
+			_, success := match(newImage(0, 1, 2, 3))
+			Expect(success).To(BeTrue())
+			Expect(afero.ReadFile(fs, path)).To(Equal(encode(newImage(0, 1, 2, 3))))
+		})
+	})
+
+	When("the snapshot exists", func() {
+		BeforeEach(func() {
+			Expect((&SingleStorage{Path: path, Fs: fs}).Write(encode(newImage(0, 100, 200, 255)))).To(Succeed())
+		})
+
+		It("should match the same image", func() {
+			_, success := match(newImage(0, 100, 200, 255))
+			Expect(success).To(BeTrue())
+		})
+
+		It("should not match when a pixel changed", func() {
+			_, success := match(newImage(0, 101, 200, 255))
+			Expect(success).To(BeFalse())
+		})
+
+		It("should not match when the size changed", func() {
+			options = append(options, WithMaxDiffRatio(1))
+			_, success := match(newImage(0, 100, 200, 255, 0, 0))
+			Expect(success).To(BeFalse())
+		})
+
+		When("threshold is set", func() {
+			BeforeEach(func() {
+				options = append(options, WithThreshold(5))
+			})
+
+			It("should match when changes are within the threshold", func() {
+				_, success := match(newImage(5, 95, 200, 255))
+				Expect(success).To(BeTrue())
+			})
+
+			It("should not match when changes exceed the threshold", func() {
+				_, success := match(newImage(6, 100, 200, 255))
+				Expect(success).To(BeFalse())
+			})
+		})
+
+		When("max diff ratio is set", func() {
+			BeforeEach(func() {
+				options = append(options, WithMaxDiffRatio(0.25))
+			})
+
+			It("should match when few pixels changed", func() {
+				_, success := match(newImage(1, 100, 200, 255))
+				Expect(success).To(BeTrue())
+			})
+
+			It("should not match when too many pixels changed", func() {
+				_, success := match(newImage(1, 101, 200, 255))
+				Expect(success).To(BeFalse())
+			})
+		})
+
+		Describe("failure message", func() {
+			var message string
+			diffPath := func() string {
+				return filepath.Join(fs.path, "foo", "bar.diff.png")
+			}
+
+			BeforeEach(func() {
+				matcher, _ := match(newImage(0, 101, 200, 255))
+				message = matcher.FailureMessage(newImage(0, 101, 200, 255))
+			})
+
+			It("should describe changed pixels", func() {
+				Expect(message).To(ContainSubstring("1 of 4 pixels (25.00%) differ"))
+				Expect(message).To(ContainSubstring("diff image: " + diffPath()))
+			})
+
+			It("should write a diff image", func() {
+				data, err := afero.ReadFile(fs, diffPath())
+				Expect(err).NotTo(HaveOccurred())
+
+				img, err := png.Decode(bytes.NewReader(data))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(img.Bounds().Size()).To(Equal(image.Pt(6, 2)))
+				Expect(color.NRGBAModel.Convert(img.At(3, 0))).To(Equal(color.NRGBA{R: 0xff, A: 0xff}))
+			})
+
+			It("should remove the diff image when the image matches", func() {
+				_, success := match(newImage(0, 100, 200, 255))
+				Expect(success).To(BeTrue())
+				Expect(afero.Exists(fs, diffPath())).To(BeFalse())
+			})
+		})
+	})
+})
+
+var _ = Describe("ImageSerializer", func() {
+	serialize := func(input interface{}) ([]byte, error) {
+		var buf bytes.Buffer
+		err := (&ImageSerializer{}).Serialize(&buf, input)
+
+		return buf.Bytes(), err
+	}
+
+	It("should decode encoded images", func() {
+		img := image.NewGray(image.Rect(0, 0, 1, 1))
+		expected, err := serialize(img)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(serialize(expected)).To(Equal(expected))
+	})
+
+	It("should return error for unsupported types", func() {
+		_, err := serialize("foo")
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,386 @@
+package goldga
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/onsi/ginkgo/v2/types"
+	"github.com/spf13/afero"
+)
+
+const snapshotWriteReportEntryName = "goldga:write"
+
+// nolint: gochecknoglobals
+var defaultSnapshotIndex = &snapshotIndex{}
+
+// Flush writes snapshots buffered by SuiteStorage with Batch enabled. Ginkgo
+// suites flush them at the end of the suite automatically. Other test
+// frameworks must call it after all tests, e.g. in TestMain. Snapshots written
+// in Ginkgo specs are staged for the end of the suite and are not written by
+// Flush, so it is safe to call it in AfterSuite.
+func Flush() error {
+	return defaultSnapshotIndex.flush(true)
+}
+
+// snapshotWrite is a buffered write of a snapshot.
+type snapshotWrite struct {
+	Path            string            `json:"path"`
+	Name            string            `json:"name"`
+	Data            []byte            `json:"data"`
+	Binary          bool              `json:"binary,omitempty"`
+	KeepLineEndings bool              `json:"keep_line_endings,omitempty"`
+	Metadata        *SnapshotMetadata `json:"metadata,omitempty"`
+
+	// reported is true when the write is staged and attached to the current
+	// spec as a report entry, so it is written by reportAfterSuite.
+	reported bool
+}
+
+// snapshotWriteRef is the report entry of a staged write. It only refers to
+// the staging file, so snapshots are not included in Ginkgo reports.
+type snapshotWriteRef struct {
+	File string `json:"file"`
+	Path string `json:"path"`
+	Name string `json:"name"`
+}
+
+func (w *snapshotWrite) apply(data *suiteData) {
+	data.setSnapshot(w.Name, string(w.Data), w.Binary)
+
+	if w.Metadata != nil {
+		data.Metadata[w.Name] = w.Metadata
+	}
+}
+
+type indexKey struct {
+	fs   afero.Fs
+	path string
+}
+
+// snapshotIndex keeps parsed golden files and buffered writes in memory, so
+// golden files are decoded once when they are not modified, and written once
+// at the end of the suite.
+type snapshotIndex struct {
+	mu    sync.Mutex
+	files map[indexKey]*indexedFile
+
+	// staging is the file of the process in the temp directory, in which
+	// writes of specs are appended as JSON lines.
+	staging afero.File
+}
+
+type indexedFile struct {
+	writes []*snapshotWrite
+
+	// cache is keyed by KeepLineEndings, which changes how the file is decoded.
+	cache map[bool]*indexedData
+}
+
+type indexedData struct {
+	data    *suiteData
+	exists  bool
+	modTime time.Time
+	size    int64
+
+	// applied is the number of buffered writes applied to data.
+	applied int
+}
+
+func (idx *snapshotIndex) getFile(fs afero.Fs, path string) *indexedFile {
+	if idx.files == nil {
+		idx.files = map[indexKey]*indexedFile{}
+	}
+
+	key := indexKey{fs: fs, path: path}
+	file, ok := idx.files[key]
+
+	if !ok {
+		file = &indexedFile{cache: map[bool]*indexedData{}}
+		idx.files[key] = file
+	}
+
+	return file
+}
+
+// get returns the parsed file with buffered writes applied. The file is parsed
+// again when its modification time or size changed. The returned data must not
+// be modified.
+func (idx *snapshotIndex) get(s *SuiteStorage) (*suiteData, error) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	info, err := s.Fs.Stat(s.Path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to check file exist: %w", err)
+	}
+
+	file := idx.getFile(s.Fs, s.Path)
+	cached := file.cache[s.KeepLineEndings]
+
+	if cached == nil || cached.exists != (info != nil) || (info != nil && (!cached.modTime.Equal(info.ModTime()) || cached.size != info.Size())) {
+		cached = &indexedData{}
+
+		if info != nil {
+			data, err := s.readSuiteData()
+			if err != nil && !errors.Is(err, afero.ErrFileNotFound) {
+				return nil, err
+			}
+
+			cached.data = data
+			cached.exists = true
+			cached.modTime = info.ModTime()
+			cached.size = info.Size()
+		}
+
+		file.cache[s.KeepLineEndings] = cached
+	}
+
+	if cached.applied < len(file.writes) {
+		if cached.data == nil {
+			cached.data = newSuiteData()
+		}
+
+		for _, w := range file.writes[cached.applied:] {
+			w.apply(cached.data)
+		}
+
+		cached.applied = len(file.writes)
+	}
+
+	if cached.data == nil {
+		return nil, afero.ErrFileNotFound
+	}
+
+	return cached.data, nil
+}
+
+// write buffers the write. When it is called in a Ginkgo spec, the write is
+// also staged in a temp file, and a reference to it is attached to the spec as
+// a report entry, because reportAfterSuite only runs on the first process when
+// specs run in parallel.
+func (idx *snapshotIndex) write(fs afero.Fs, w *snapshotWrite) error {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	if fs == defaultFs && isGinkgoSpecRunning() {
+		ref, err := idx.stage(fs, w)
+		if err != nil {
+			return err
+		}
+
+		addReportEntry(snapshotWriteReportEntryName, ref)
+		w.reported = true
+	}
+
+	file := idx.getFile(fs, w.Path)
+	file.writes = append(file.writes, w)
+
+	return nil
+}
+
+func (idx *snapshotIndex) stage(fs afero.Fs, w *snapshotWrite) (*snapshotWriteRef, error) {
+	if idx.staging == nil {
+		file, err := afero.TempFile(fs, "", "goldga-*.jsonl")
+		if err != nil {
+			return nil, fmt.Errorf("failed to create staging file: %w", err)
+		}
+
+		idx.staging = file
+	}
+
+	data, err := json.Marshal(w)
+	if err != nil {
+		return nil, fmt.Errorf("json encode error: %w", err)
+	}
+
+	if _, err := idx.staging.Write(append(data, '\n')); err != nil {
+		return nil, fmt.Errorf("failed to write staging file: %w", err)
+	}
+
+	return &snapshotWriteRef{
+		File: idx.staging.Name(),
+		Path: w.Path,
+		Name: w.Name,
+	}, nil
+}
+
+// flush writes buffered writes which are not reported to golden files.
+// Reported writes are written by flushReportedWrites, so they are kept in the
+// index until the end of the suite, when final is true and the index is
+// cleared.
+func (idx *snapshotIndex) flush(final bool) error {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	keys := make([]indexKey, 0, len(idx.files))
+
+	for key := range idx.files {
+		keys = append(keys, key)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].path < keys[j].path
+	})
+
+	var messages []string
+
+	for _, key := range keys {
+		file := idx.files[key]
+
+		var writes, reported []*snapshotWrite
+
+		for _, w := range file.writes {
+			if w.reported {
+				reported = append(reported, w)
+			} else {
+				writes = append(writes, w)
+			}
+		}
+
+		if err := applySnapshotWrites(key.fs, key.path, writes); err != nil {
+			messages = append(messages, err.Error())
+		}
+
+		// Cached data is decoded again, because the file is modified and
+		// applied writes are removed.
+		file.writes = reported
+		file.cache = map[bool]*indexedData{}
+	}
+
+	if final {
+		idx.files = nil
+
+		// The staging file is removed by flushReportedWrites.
+		if idx.staging != nil {
+			_ = idx.staging.Close()
+			idx.staging = nil
+		}
+	}
+
+	if len(messages) > 0 {
+		return errors.New(strings.Join(messages, "\n"))
+	}
+
+	return nil
+}
+
+// applySnapshotWrites writes snapshots to the golden file at once.
+func applySnapshotWrites(fs afero.Fs, path string, writes []*snapshotWrite) error {
+	if len(writes) == 0 {
+		return nil
+	}
+
+	storage := &SuiteStorage{Path: path, Fs: fs}
+
+	for _, w := range writes {
+		storage.KeepLineEndings = storage.KeepLineEndings || w.KeepLineEndings
+	}
+
+	err := storage.updateSuiteData(func(data *suiteData) {
+		for _, w := range writes {
+			w.apply(data)
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// flushReportedWrites writes snapshots staged by specs of all parallel
+// processes, and then buffered writes which are not reported, e.g. written
+// outside specs. Staging files are removed afterwards.
+func flushReportedWrites(fs afero.Fs, report types.Report) error {
+	var files []string
+
+	seen := map[string]bool{}
+
+	for _, spec := range report.SpecReports {
+		for _, entry := range spec.ReportEntries {
+			if entry.Name != snapshotWriteReportEntryName {
+				continue
+			}
+
+			ref := &snapshotWriteRef{}
+
+			if err := decodeReportEntry(entry, ref); err != nil {
+				return fmt.Errorf("failed to collect snapshots: %w", err)
+			}
+
+			if !seen[ref.File] {
+				seen[ref.File] = true
+				files = append(files, ref.File)
+			}
+		}
+	}
+
+	defer func() {
+		for _, file := range files {
+			_ = fs.Remove(file)
+		}
+	}()
+
+	writes := map[string][]*snapshotWrite{}
+
+	for _, file := range files {
+		staged, err := readStagedWrites(fs, file)
+		if err != nil {
+			return fmt.Errorf("failed to collect snapshots: %w", err)
+		}
+
+		for _, w := range staged {
+			writes[w.Path] = append(writes[w.Path], w)
+		}
+	}
+
+	paths := make([]string, 0, len(writes))
+
+	for path := range writes {
+		paths = append(paths, path)
+	}
+
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		if err := applySnapshotWrites(fs, path, writes[path]); err != nil {
+			return err
+		}
+	}
+
+	return defaultSnapshotIndex.flush(false)
+}
+
+func readStagedWrites(fs afero.Fs, path string) ([]*snapshotWrite, error) {
+	file, err := fs.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open staging file: %w", err)
+	}
+
+	defer file.Close()
+
+	var writes []*snapshotWrite
+
+	decoder := json.NewDecoder(file)
+
+	for {
+		w := &snapshotWrite{}
+
+		if err := decoder.Decode(w); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("json decode error: %w", err)
+		}
+
+		writes = append(writes, w)
+	}
+
+	return writes, nil
+}
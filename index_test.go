@@ -0,0 +1,196 @@
+package goldga
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	"github.com/onsi/ginkgo/v2/types"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/afero"
+)
+
+var _ = Describe("SuiteStorage with Batch", func() {
+	var (
+		fs      *tempFs
+		index   *snapshotIndex
+		storage *SuiteStorage
+	)
+
+	BeforeEach(func() {
+		fs = newTempFs()
+		index = &snapshotIndex{}
+		storage = &SuiteStorage{
+			Path:  filepath.Join(fs.path, "foo.golden"),
+			Name:  "Foo",
+			Fs:    fs,
+			Batch: true,
+			index: index,
+		}
+	})
+
+	AfterEach(func() {
+		Expect(index.flush(false)).To(Succeed())
+		fs.Teardown()
+	})
+
+	newStorage := func(name string) *SuiteStorage {
+		s := *storage
+		s.Name = name
+
+		return &s
+	}
+
+	It("should buffer writes until the index is flushed", func() {
+		Expect(storage.Write([]byte("foo"))).To(Succeed())
+		Expect(newStorage("Bar").Write([]byte("bar"))).To(Succeed())
+		Expect(afero.Exists(fs, storage.Path)).To(BeFalse())
+		Expect(storage.Read()).To(Equal([]byte("foo")))
+
+		Expect(index.flush(false)).To(Succeed())
+		Expect((&SuiteStorage{Path: storage.Path, Fs: fs}).Keys()).To(Equal([]string{"Bar", "Foo"}))
+		Expect(storage.Read()).To(Equal([]byte("foo")))
+	})
+
+	It("should keep snapshots in the file", func() {
+		Expect((&SuiteStorage{Path: storage.Path, Name: "Bar", Fs: fs}).Write([]byte("bar"))).To(Succeed())
+		Expect(storage.Write([]byte("foo"))).To(Succeed())
+		Expect(newStorage("Bar").Read()).To(Equal([]byte("bar")))
+
+		Expect(index.flush(false)).To(Succeed())
+		Expect(newStorage("Bar").Read()).To(Equal([]byte("bar")))
+		Expect(storage.Read()).To(Equal([]byte("foo")))
+	})
+
+	It("should read the file again when it is modified", func() {
+		Expect(afero.WriteFile(fs, storage.Path, []byte("[snapshots]\nFoo = 'a'\n"), 0o644)).To(Succeed())
+		Expect(storage.Read()).To(Equal([]byte("a")))
+
+		Expect(afero.WriteFile(fs, storage.Path, []byte("[snapshots]\nFoo = 'abc'\n"), 0o644)).To(Succeed())
+		Expect(storage.Read()).To(Equal([]byte("abc")))
+	})
+
+	It("should keep reported writes until the end of the suite", func() {
+		index.getFile(fs, storage.Path).writes = []*snapshotWrite{
+			{Path: storage.Path, Name: "Foo", Data: []byte("foo"), reported: true},
+		}
+		Expect(newStorage("Bar").Write([]byte("bar"))).To(Succeed())
+
+		Expect(index.flush(false)).To(Succeed())
+		Expect((&SuiteStorage{Path: storage.Path, Fs: fs}).Keys()).To(Equal([]string{"Bar"}))
+		Expect(storage.Read()).To(Equal([]byte("foo")))
+
+		Expect(index.flush(true)).To(Succeed())
+		Expect(index.files).To(BeNil())
+	})
+
+	It("should write metadata", func() {
+		metadata := &SnapshotMetadata{Serializer: "foo"}
+		Expect(storage.WriteWithMetadata([]byte("foo"), metadata)).To(Succeed())
+		Expect(storage.ReadMetadata()).To(Equal(metadata))
+
+		Expect(index.flush(false)).To(Succeed())
+		storage.Batch = false
+		Expect(storage.ReadMetadata()).To(Equal(metadata))
+	})
+})
+
+var _ = Describe("flushReportedWrites", func() {
+	var fs *tempFs
+
+	BeforeEach(func() {
+		fs = newTempFs()
+	})
+
+	AfterEach(func() {
+		fs.Teardown()
+	})
+
+	It("should write snapshots staged by specs", func() {
+		path := filepath.Join(fs.path, "foo.golden")
+		newStagingFile := func(name string, writes ...*snapshotWrite) string {
+			file := filepath.Join(fs.path, name)
+			var buf bytes.Buffer
+
+			for _, w := range writes {
+				Expect(json.NewEncoder(&buf).Encode(w)).To(Succeed())
+			}
+
+			Expect(afero.WriteFile(fs, file, buf.Bytes(), 0o644)).To(Succeed())
+
+			return file
+		}
+		newSpecReport := func(file string, writes ...*snapshotWrite) types.SpecReport {
+			spec := types.SpecReport{}
+
+			for _, w := range writes {
+				spec.ReportEntries = append(spec.ReportEntries, types.ReportEntry{
+					Name:  snapshotWriteReportEntryName,
+					Value: types.WrapEntryValue(&snapshotWriteRef{File: file, Path: w.Path, Name: w.Name}),
+				})
+			}
+
+			return spec
+		}
+
+		a := &snapshotWrite{Path: path, Name: "A", Data: []byte("a")}
+		b := &snapshotWrite{Path: path, Name: "B", Data: []byte{0xff}}
+		c := &snapshotWrite{Path: path, Name: "C", Data: []byte("c"), Binary: true}
+		file1 := newStagingFile("1.jsonl", a)
+		file2 := newStagingFile("2.jsonl", b, c)
+
+		Expect(flushReportedWrites(fs, types.Report{
+			SpecReports: types.SpecReports{
+				newSpecReport(file1, a),
+				newSpecReport(file2, b, c),
+			},
+		})).To(Succeed())
+
+		storage := &SuiteStorage{Path: path, Fs: fs}
+		Expect(storage.Keys()).To(Equal([]string{"A", "B", "C"}))
+
+		storage.Name = "B"
+		Expect(storage.Read()).To(Equal([]byte{0xff}))
+
+		content, err := afero.ReadFile(fs, path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(content)).To(HaveSuffix("[binary]\n\"B\" = \"/w==\"\n\"C\" = \"Yw==\"\n"))
+
+		Expect(afero.Exists(fs, file1)).To(BeFalse())
+		Expect(afero.Exists(fs, file2)).To(BeFalse())
+	})
+
+	It("should fail when the staging file does not exist", func() {
+		Expect(flushReportedWrites(fs, types.Report{
+			SpecReports: types.SpecReports{{
+				ReportEntries: types.ReportEntries{{
+					Name:  snapshotWriteReportEntryName,
+					Value: types.WrapEntryValue(&snapshotWriteRef{File: filepath.Join(fs.path, "missing.jsonl")}),
+				}},
+			}},
+		})).To(MatchError(ContainSubstring("failed to open staging file")))
+	})
+})
+
+var _ = Describe("snapshotIndex.stage", func() {
+	It("should stage writes in a file and only refer to it", func() {
+		fs := afero.NewMemMapFs()
+		idx := &snapshotIndex{}
+		w := &snapshotWrite{Path: "foo.golden", Name: "Foo", Data: []byte("payload")}
+
+		ref, err := idx.stage(fs, w)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ref.Path).To(Equal("foo.golden"))
+		Expect(ref.Name).To(Equal("Foo"))
+
+		data, err := json.Marshal(ref)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(data)).NotTo(ContainSubstring("cGF5bG9hZA"))
+
+		Expect(idx.staging.Close()).To(Succeed())
+		Expect(readStagedWrites(fs, ref.File)).To(Equal([]*snapshotWrite{
+			{Path: "foo.golden", Name: "Foo", Data: []byte("payload")},
+		}))
+	})
+})
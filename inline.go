@@ -0,0 +1,241 @@
+package goldga
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/onsi/ginkgo/v2/types"
+	"github.com/spf13/afero"
+)
+
+const (
+	inlineReportEntryName = "goldga:inline"
+	inlineFuncName        = "MatchInline"
+)
+
+// MatchInline compares the actual value with the given snapshot. When the
+// snapshot is written, the string literal passed to MatchInline is rewritten
+// in the test source. Updates are applied at the end of the suite.
+func MatchInline(snapshot string, options ...Option) *Matcher {
+	_, file, line, ok := runtime.Caller(1)
+	if !ok {
+		panic("failed to get the caller of MatchInline")
+	}
+
+	return Match(append([]Option{WithStorage(&InlineStorage{
+		Snapshot: snapshot,
+		File:     file,
+		Line:     line,
+		Fs:       defaultFs,
+	})}, options...)...)
+}
+
+var _ Storage = (*InlineStorage)(nil)
+
+// InlineStorage stores a snapshot as a string literal in the Go source file.
+// File and Line point to the MatchInline call.
+type InlineStorage struct {
+	Snapshot string
+	File     string
+	Line     int
+	Fs       afero.Fs
+}
+
+type inlineSnapshot struct {
+	File  string `json:"file"`
+	Line  int    `json:"line"`
+	Value string `json:"value"`
+}
+
+func (s *InlineStorage) Read() ([]byte, error) {
+	if s.Snapshot == "" {
+		return nil, afero.ErrFileNotFound
+	}
+
+	return []byte(s.Snapshot), nil
+}
+
+// Write records the new snapshot. When a Ginkgo spec is running, the source is
+// rewritten at the end of the suite, because rewriting it immediately would
+// change the line numbers of other MatchInline calls in the same file.
+func (s *InlineStorage) Write(data []byte) error {
+	snapshot := inlineSnapshot{
+		File:  s.File,
+		Line:  s.Line,
+		Value: string(data),
+	}
+
+	if isGinkgoSpecRunning() {
+		addReportEntry(inlineReportEntryName, snapshot)
+
+		return nil
+	}
+
+	return rewriteInlineSnapshots(s.Fs, s.File, []inlineSnapshot{snapshot})
+}
+
+func collectInlineSnapshots(report types.Report) (map[string][]inlineSnapshot, error) {
+	result := map[string][]inlineSnapshot{}
+	seen := map[string]map[int]string{}
+
+	for _, spec := range report.SpecReports {
+		for _, entry := range spec.ReportEntries {
+			if entry.Name != inlineReportEntryName {
+				continue
+			}
+
+			var snapshot inlineSnapshot
+
+			if err := decodeReportEntry(entry, &snapshot); err != nil {
+				return nil, err
+			}
+
+			if seen[snapshot.File] == nil {
+				seen[snapshot.File] = map[int]string{}
+			}
+
+			if value, ok := seen[snapshot.File][snapshot.Line]; ok {
+				if value != snapshot.Value {
+					return nil, fmt.Errorf("%s:%d: conflicting inline snapshots, a MatchInline call can only match a single value",
+						snapshot.File, snapshot.Line)
+				}
+
+				continue
+			}
+
+			seen[snapshot.File][snapshot.Line] = snapshot.Value
+			result[snapshot.File] = append(result[snapshot.File], snapshot)
+		}
+	}
+
+	return result, nil
+}
+
+func writeInlineSnapshots(fs afero.Fs, report types.Report) error {
+	snapshots, err := collectInlineSnapshots(report)
+	if err != nil {
+		return err
+	}
+
+	files := make([]string, 0, len(snapshots))
+
+	for file := range snapshots {
+		files = append(files, file)
+	}
+
+	sort.Strings(files)
+
+	for _, file := range files {
+		if err := rewriteInlineSnapshots(fs, file, snapshots[file]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// quoteInlineSnapshot returns a raw string literal when possible, so
+// multi-line snapshots remain readable in the source.
+func quoteInlineSnapshot(s string) string {
+	if utf8.ValidString(s) && !strings.ContainsAny(s, "`\r") {
+		return "`" + s + "`"
+	}
+
+	return strconv.Quote(s)
+}
+
+func isInlineCall(call *ast.CallExpr) bool {
+	switch fn := call.Fun.(type) {
+	case *ast.Ident:
+		return fn.Name == inlineFuncName
+	case *ast.SelectorExpr:
+		return fn.Sel.Name == inlineFuncName
+	}
+
+	return false
+}
+
+func rewriteInlineSnapshots(fs afero.Fs, path string, snapshots []inlineSnapshot) error {
+	src, err := afero.ReadFile(fs, path)
+	if err != nil {
+		return fmt.Errorf("failed to read file: %w", err)
+	}
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, path, src, parser.ParseComments)
+	if err != nil {
+		return fmt.Errorf("failed to parse file: %w", err)
+	}
+
+	values := map[int]string{}
+
+	for _, s := range snapshots {
+		values[s.Line] = s.Value
+	}
+
+	ast.Inspect(file, func(node ast.Node) bool {
+		call, ok := node.(*ast.CallExpr)
+		if !ok || !isInlineCall(call) {
+			return true
+		}
+
+		start, end := fset.Position(call.Pos()).Line, fset.Position(call.End()).Line
+
+		for line := start; line <= end; line++ {
+			value, ok := values[line]
+			if !ok {
+				continue
+			}
+
+			lit := &ast.BasicLit{
+				ValuePos: call.Lparen + 1,
+				Kind:     token.STRING,
+				Value:    quoteInlineSnapshot(value),
+			}
+
+			if len(call.Args) > 0 {
+				lit.ValuePos = call.Args[0].Pos()
+				call.Args[0] = lit
+			} else {
+				call.Args = []ast.Expr{lit}
+			}
+
+			delete(values, line)
+
+			break
+		}
+
+		return true
+	})
+
+	if len(values) > 0 {
+		return errors.New("failed to find MatchInline calls in " + path)
+	}
+
+	var buf bytes.Buffer
+
+	if err := format.Node(&buf, fset, file); err != nil {
+		return fmt.Errorf("failed to format file: %w", err)
+	}
+
+	if bytes.Equal(buf.Bytes(), src) {
+		return nil
+	}
+
+	if err := afero.WriteFile(fs, path, buf.Bytes(), os.ModePerm); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,132 @@
+package goldga
+
+import (
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	"github.com/onsi/ginkgo/v2/types"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/afero"
+)
+
+var _ = Describe("MatchInline", func() {
+	It("should match the inline snapshot", func() {
+		Expect("foo").To(MatchInline(`(string) (len=3) "foo"
+`))
+	})
+
+	It("should use the caller location", func() {
+		matcher := MatchInline("foo")
+		storage, ok := matcher.Storage.(*InlineStorage)
+		Expect(ok).To(BeTrue())
+		Expect(storage.File).To(HaveSuffix("inline_test.go"))
+		Expect(storage.Line).To(Equal(CurrentSpecReport().LineNumber() + 1))
+	})
+})
+
+var _ = Describe("InlineStorage", func() {
+	Describe("Read", func() {
+		It("should return the snapshot", func() {
+			s := &InlineStorage{Snapshot: "foo"}
+			Expect(s.Read()).To(Equal([]byte("foo")))
+		})
+
+		It("should return not found error when the snapshot is empty", func() {
+			s := &InlineStorage{}
+			_, err := s.Read()
+			Expect(err).To(Equal(afero.ErrFileNotFound))
+		})
+	})
+})
+
+var _ = Describe("rewriteInlineSnapshots", func() {
+	var (
+		fs   *tempFs
+		path string
+	)
+
+	BeforeEach(func() {
+		fs = newTempFs()
+		path = filepath.Join(fs.path, "foo_test.go")
+		Expect(afero.WriteFile(fs, path, []byte(`package foo
+
+func TestFoo() {
+	// Comment
+	Expect(1).To(goldga.MatchInline(""))
+	Expect(2).To(MatchInline("",
+		goldga.WithDescription("foo"),
+	))
+}
+`), 0o644)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		fs.Teardown()
+	})
+
+	readFile := func() string {
+		data, err := afero.ReadFile(fs, path)
+		Expect(err).NotTo(HaveOccurred())
+
+		return string(data)
+	}
+
+	It("should rewrite string literals", func() {
+		Expect(rewriteInlineSnapshots(fs, path, []inlineSnapshot{
+			{File: path, Line: 5, Value: "one\n"},
+			{File: path, Line: 7, Value: "`two`"},
+		})).To(Succeed())
+		Expect(readFile()).To(Equal(`package foo
+
+func TestFoo() {
+	// Comment
+	Expect(1).To(goldga.MatchInline(` + "`one\n`" + `))
+	Expect(2).To(MatchInline("` + "`two`" + `",
+		goldga.WithDescription("foo"),
+	))
+}
+`))
+	})
+
+	It("should return error when the call does not exist", func() {
+		Expect(rewriteInlineSnapshots(fs, path, []inlineSnapshot{
+			{File: path, Line: 2, Value: "one"},
+		})).NotTo(Succeed())
+	})
+})
+
+var _ = Describe("collectInlineSnapshots", func() {
+	newReport := func(snapshots ...inlineSnapshot) types.Report {
+		var spec types.SpecReport
+
+		for _, s := range snapshots {
+			spec.ReportEntries = append(spec.ReportEntries, types.ReportEntry{
+				Name:  inlineReportEntryName,
+				Value: types.WrapEntryValue(s),
+			})
+		}
+
+		return types.Report{SpecReports: types.SpecReports{spec}}
+	}
+
+	It("should group snapshots by file", func() {
+		result, err := collectInlineSnapshots(newReport(
+			inlineSnapshot{File: "a", Line: 1, Value: "1"},
+			inlineSnapshot{File: "a", Line: 1, Value: "1"},
+			inlineSnapshot{File: "b", Line: 2, Value: "2"},
+		))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(Equal(map[string][]inlineSnapshot{
+			"a": {{File: "a", Line: 1, Value: "1"}},
+			"b": {{File: "b", Line: 2, Value: "2"}},
+		}))
+	})
+
+	It("should return error when values conflict", func() {
+		_, err := collectInlineSnapshots(newReport(
+			inlineSnapshot{File: "a", Line: 1, Value: "1"},
+			inlineSnapshot{File: "a", Line: 1, Value: "2"},
+		))
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,162 @@
+package goldga
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/spf13/afero"
+)
+
+const (
+	lockFileSuffix    = ".lock"
+	lockRetryInterval = 10 * time.Millisecond
+	lockStaleTimeout  = time.Minute
+)
+
+// lockFile acquires an exclusive lock on the given path by creating a lock
+// file next to it. The lock works across processes, which is required when
+// specs run in parallel with `ginkgo -p`. Lock files older than
+// lockStaleTimeout are considered abandoned and removed. Each lock file
+// contains a unique owner token, so a process never releases a lock which was
+// taken over by another process.
+func lockFile(fs afero.Fs, path string) (func() error, error) {
+	lockPath := path + lockFileSuffix
+
+	token, err := newLockToken()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		acquired, err := createLockFile(fs, lockPath, token)
+		if err != nil {
+			return nil, err
+		}
+
+		if acquired && isLockOwner(fs, lockPath, token) {
+			return func() error {
+				if !isLockOwner(fs, lockPath, token) {
+					return nil
+				}
+
+				if err := fs.Remove(lockPath); err != nil {
+					return fmt.Errorf("failed to remove lock file: %w", err)
+				}
+
+				return nil
+			}, nil
+		}
+
+		if !acquired && removeStaleLock(fs, lockPath) {
+			continue
+		}
+
+		time.Sleep(lockRetryInterval)
+	}
+}
+
+func newLockToken() (string, error) {
+	nonce := make([]byte, 8)
+
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate lock token: %w", err)
+	}
+
+	return fmt.Sprintf("%d-%x", os.Getpid(), nonce), nil
+}
+
+// createLockFile creates the lock file with the token, and returns false when
+// the lock file already exists.
+func createLockFile(fs afero.Fs, lockPath, token string) (bool, error) {
+	file, err := fs.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+	if errors.Is(err, os.ErrExist) {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, fmt.Errorf("failed to create lock file: %w", err)
+	}
+
+	if _, err := io.WriteString(file, token); err != nil {
+		file.Close()
+		_ = fs.Remove(lockPath)
+
+		return false, fmt.Errorf("failed to write lock file: %w", err)
+	}
+
+	if err := file.Close(); err != nil {
+		return false, fmt.Errorf("failed to close lock file: %w", err)
+	}
+
+	return true, nil
+}
+
+func isLockOwner(fs afero.Fs, lockPath, token string) bool {
+	data, err := afero.ReadFile(fs, lockPath)
+
+	return err == nil && string(data) == token
+}
+
+func isLockStale(fs afero.Fs, path string) bool {
+	info, err := fs.Stat(path)
+
+	return err == nil && time.Since(info.ModTime()) > lockStaleTimeout
+}
+
+// removeStaleLock removes the lock file when it is stale, and reports whether
+// the lock should be acquired again immediately. The lock file is renamed to a
+// unique name before it is removed, so only one process removes it. If the
+// lock was replaced after it was checked, its owner finds a different token in
+// the lock file and does not remove it when the lock is released.
+func removeStaleLock(fs afero.Fs, lockPath string) bool {
+	if !isLockStale(fs, lockPath) {
+		return false
+	}
+
+	stalePath := fmt.Sprintf("%s.%d.%d.stale", lockPath, os.Getpid(), time.Now().UnixNano())
+
+	if err := fs.Rename(lockPath, stalePath); err != nil {
+		// Another process removed or renamed the lock file first.
+		return true
+	}
+
+	_ = fs.Remove(stalePath)
+
+	return true
+}
+
+// writeFileAtomic writes data to a temporary file and renames it to the given
+// path, so readers never see a partially written file.
+func writeFileAtomic(fs afero.Fs, path string, write func(file afero.File) error) error {
+	tmpPath := fmt.Sprintf("%s.%d.tmp", path, os.Getpid())
+
+	file, err := fs.Create(tmpPath)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+
+	if err := write(file); err != nil {
+		file.Close()
+		_ = fs.Remove(tmpPath)
+
+		return err
+	}
+
+	if err := file.Close(); err != nil {
+		_ = fs.Remove(tmpPath)
+
+		return fmt.Errorf("failed to close file: %w", err)
+	}
+
+	if err := fs.Rename(tmpPath, path); err != nil {
+		_ = fs.Remove(tmpPath)
+
+		return fmt.Errorf("failed to rename file: %w", err)
+	}
+
+	return nil
+}
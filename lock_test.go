@@ -0,0 +1,86 @@
+package goldga
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/afero"
+)
+
+var _ = Describe("lockFile", func() {
+	var (
+		fs   *tempFs
+		path string
+	)
+
+	BeforeEach(func() {
+		fs = newTempFs()
+		path = filepath.Join(fs.path, "foo.golden")
+	})
+
+	AfterEach(func() {
+		fs.Teardown()
+	})
+
+	It("should wait until the lock is released", func() {
+		unlock, err := lockFile(fs, path)
+		Expect(err).NotTo(HaveOccurred())
+
+		acquired := make(chan struct{})
+
+		go func() {
+			defer GinkgoRecover()
+
+			unlock, err := lockFile(fs, path)
+			Expect(err).NotTo(HaveOccurred())
+			close(acquired)
+			Expect(unlock()).To(Succeed())
+		}()
+
+		Consistently(acquired, 50*time.Millisecond).ShouldNot(BeClosed())
+		Expect(unlock()).To(Succeed())
+		Eventually(acquired).Should(BeClosed())
+	})
+
+	It("should remove stale lock files", func() {
+		lockPath := path + lockFileSuffix
+		Expect(afero.WriteFile(fs, lockPath, nil, os.ModePerm)).To(Succeed())
+
+		staleTime := time.Now().Add(-2 * lockStaleTimeout)
+		Expect(fs.Chtimes(lockPath, staleTime, staleTime)).To(Succeed())
+
+		unlock, err := lockFile(fs, path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(unlock()).To(Succeed())
+		Expect(afero.Exists(fs, lockPath)).To(BeFalse())
+		Expect(afero.Glob(fs, lockPath+"*")).To(BeEmpty())
+	})
+
+	It("should not remove lock files which are not stale", func() {
+		lockPath := path + lockFileSuffix
+		Expect(afero.WriteFile(fs, lockPath, nil, os.ModePerm)).To(Succeed())
+
+		Expect(removeStaleLock(fs, lockPath)).To(BeFalse())
+		Expect(afero.Exists(fs, lockPath)).To(BeTrue())
+	})
+
+	It("should write the owner token to the lock file", func() {
+		unlock, err := lockFile(fs, path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(afero.ReadFile(fs, path+lockFileSuffix)).To(MatchRegexp(`^\d+-[0-9a-f]{16}$`))
+		Expect(unlock()).To(Succeed())
+	})
+
+	It("should not remove the lock file when it was taken over by another process", func() {
+		lockPath := path + lockFileSuffix
+		unlock, err := lockFile(fs, path)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(afero.WriteFile(fs, lockPath, []byte("other"), os.ModePerm)).To(Succeed())
+		Expect(unlock()).To(Succeed())
+		Expect(afero.ReadFile(fs, lockPath)).To(Equal([]byte("other")))
+	})
+})
@@ -11,14 +11,34 @@ import (
 	"github.com/spf13/afero"
 )
 
+// ErrSnapshotNotFound is returned when a snapshot does not exist and the
+// update mode or CI mode does not allow to create it.
+var ErrSnapshotNotFound = errors.New("snapshot not found")
+
+// ErrSnapshotPending is returned when a new snapshot is saved for review.
+var ErrSnapshotPending = errors.New("snapshot is pending review")
+
+const reviewHint = "run `go run github.com/tommy351/goldga/cmd/goldga review` to review it"
+
 type Option func(*Matcher)
 
 // WithDescription adds an optional description to the golden file, allowing multiple gold files per test.
 func WithDescription(description string) Option {
 	return func(matcher *Matcher) {
-		if s, ok := matcher.Storage.(*SuiteStorage); ok {
-			s.Name = fmt.Sprintf("%s (%s)", s.Name, description)
-		}
+		renameStorage(matcher.Storage, func(name string) string {
+			return fmt.Sprintf("%s (%s)", name, description)
+		})
+	}
+}
+
+// WithKey overrides the key of the snapshot, which defaults to the full text of
+// the spec. Unlike the spec text, the key does not change when specs are
+// reworded.
+func WithKey(key string) Option {
+	return func(matcher *Matcher) {
+		renameStorage(matcher.Storage, func(string) string {
+			return key
+		})
 	}
 }
 
@@ -50,28 +70,183 @@ func WithDiffer(differ Differ) Option {
 	}
 }
 
-func getUpdateFile() bool {
-	update, _ := strconv.ParseBool(os.Getenv("UPDATE_GOLDEN"))
+// WithStructuredDiff shows mismatches with StructuredDiffer, which parses
+// snapshots in the format of JSONSerializer or YAMLSerializer.
+func WithStructuredDiff() Option {
+	return func(matcher *Matcher) {
+		matcher.Differ = &StructuredDiffer{}
+	}
+}
+
+// WithComparator overrides the default comparator, which requires snapshots
+// to be exactly the same.
+func WithComparator(comparator Comparator) Option {
+	return func(matcher *Matcher) {
+		matcher.Comparator = comparator
+	}
+}
+
+// WithBinary is for binary snapshots, e.g. encoded protobuf messages or
+// compressed data. Strings and byte slices are stored as is, encoded as base64
+// in the golden file, and mismatches are shown as hexdumps.
+func WithBinary() Option {
+	return func(matcher *Matcher) {
+		matcher.Serializer = &StringSerializer{}
+		matcher.Differ = &HexDiffer{}
+
+		switch s := matcher.Storage.(type) {
+		case *SuiteStorage:
+			s.Binary = true
+		case *DirStorage:
+			// Binary files are stored as is, so line endings must be kept.
+			s.Ext = ".bin"
+			s.KeepLineEndings = true
+		}
+	}
+}
+
+// WithRawLineEndings keeps line endings of snapshots as is. By default, CRLF
+// is converted to LF, so golden files checked out on Windows still match.
+func WithRawLineEndings() Option {
+	return func(matcher *Matcher) {
+		switch s := matcher.Storage.(type) {
+		case *SuiteStorage:
+			s.KeepLineEndings = true
+		case *SingleStorage:
+			s.KeepLineEndings = true
+		case *DirStorage:
+			s.KeepLineEndings = true
+		}
+	}
+}
 
-	return update
+// WithLayout overrides the layout set by the GOLDGA_LAYOUT environment
+// variable. It replaces the default storage, so it must be used before options
+// which modify the storage, e.g. WithDescription.
+func WithLayout(layout Layout) Option {
+	return func(matcher *Matcher) {
+		switch s := matcher.Storage.(type) {
+		case *SuiteStorage:
+			matcher.Storage = newStorage(layout, s.Path, s.Name)
+		case *DirStorage:
+			matcher.Storage = newStorage(layout, s.Dir+GoldenFileExt, s.Name)
+		}
+	}
 }
 
+// WithScrubbers appends scrubbers which modify the serialized content.
+func WithScrubbers(scrubbers ...Scrubber) Option {
+	return func(matcher *Matcher) {
+		matcher.Scrubbers = append(matcher.Scrubbers, scrubbers...)
+	}
+}
+
+// WithMetadata enables or disables storing the serializer, the transformer and
+// the location of the spec next to snapshots. It overrides the GOLDGA_METADATA
+// environment variable. Recorded metadata is used to report a changed
+// serializer instead of a diff, even if it is disabled.
+func WithMetadata(enabled bool) Option {
+	return func(matcher *Matcher) {
+		matcher.Metadata = enabled
+	}
+}
+
+// WithUpdateMode overrides the update mode set by the UPDATE_GOLDEN environment
+// variable. Like the environment variable, it allows to create snapshots in CI
+// mode.
+func WithUpdateMode(mode UpdateMode) Option {
+	return func(matcher *Matcher) {
+		matcher.UpdateMode = mode
+		matcher.explicitUpdateMode = true
+	}
+}
+
+// WithCI enables or disables the CI mode. In CI mode, a missing snapshot fails
+// the assertion instead of being written.
+func WithCI(ci bool) Option {
+	return func(matcher *Matcher) {
+		matcher.CI = ci
+	}
+}
+
+// WithReview enables or disables the review mode. In review mode, new and
+// mismatched snapshots are saved to a pending file instead of the golden file,
+// and can be accepted or rejected one by one with the goldga command.
+func WithReview(review bool) Option {
+	return func(matcher *Matcher) {
+		matcher.Review = review
+	}
+}
+
+func getReview() bool {
+	review, _ := strconv.ParseBool(os.Getenv("GOLDGA_REVIEW"))
+
+	return review
+}
+
+// getCI returns the value of GOLDGA_CI when it is set, otherwise CI mode is
+// enabled automatically when CI=true, which most CI services set.
+func getCI() bool {
+	if v, ok := os.LookupEnv("GOLDGA_CI"); ok {
+		ci, _ := strconv.ParseBool(v)
+
+		return ci
+	}
+
+	ci, _ := strconv.ParseBool(os.Getenv("CI"))
+
+	return ci
+}
+
+// Match returns a matcher which compares the actual value with a snapshot in
+// the golden file of the current spec. Snapshots are numbered in the order of
+// Match calls in a spec, unless the key is changed by WithKey or
+// WithDescription.
 func Match(options ...Option) *Matcher {
+	name := getGinkgoTestName()
+	m := newMatcher(getGinkgoPath(), name, options)
+	m.sourceFile, m.sourceLine = getGinkgoLocation()
+	m.applyPathResolver(getGinkgoFile())
+
+	if s, ok := m.Storage.(*SuiteStorage); ok && s.Fs == defaultFs {
+		s.Batch = true
+	}
+
+	if current, ok := getStorageName(m.Storage); ok && current == name {
+		numberSnapshot(m, defaultSpecCounter.next(getGinkgoSpecID(), name))
+	}
+
+	return m
+}
+
+func newMatcher(path, name string, options []Option) *Matcher {
+	config := getConfig()
 	m := &Matcher{
 		Serializer:  DefaultSerializer,
 		Transformer: DefaultTransformer,
-		Storage: &SuiteStorage{
-			Path: getGinkgoPath(),
-			Name: getGinkgoTestName(),
-			Fs:   defaultFs,
-		},
-		Differ:     DefaultDiffer,
-		UpdateFile: getUpdateFile(),
+		Scrubbers:   append([]Scrubber(nil), config.Scrubbers...),
+		Storage:     newStorage(getLayout(config.Layout), path, name),
+		Differ:      DefaultDiffer,
+		Comparator:  DefaultComparator,
+		CI:          getCI(),
+		Review:      getReview(),
+		Metadata:    getMetadataEnabled(),
+	}
+
+	m.UpdateMode, m.explicitUpdateMode = getUpdateMode(config.UpdateMode)
+
+	for _, option := range config.Options {
+		option(m)
 	}
+
 	for _, option := range options {
 		option(m)
 	}
 
+	if s, ok := m.Storage.(*DirStorage); ok && s.Ext == "" {
+		s.Ext = getSerializerExt(m.Serializer)
+	}
+
 	return m
 }
 
@@ -80,34 +255,187 @@ var _ types.GomegaMatcher = (*Matcher)(nil)
 type Matcher struct {
 	Serializer  Serializer
 	Transformer Transformer
+	Scrubbers   []Scrubber
 	Storage     Storage
 	Differ      Differ
-	UpdateFile  bool
+	Comparator  Comparator
+	UpdateMode  UpdateMode
+	CI          bool
+	Review      bool
+	Metadata    bool
+
+	// Deprecated: Set UpdateMode to UpdateAll instead. UpdateFile takes
+	// precedence over UpdateMode when it is true.
+	UpdateFile bool
+
+	// explicitUpdateMode is true when the update mode is set by UPDATE_GOLDEN
+	// or WithUpdateMode, which overrides CI mode.
+	explicitUpdateMode bool
+
+	sourceFile string
+	sourceLine int
+
+	// fallback is read when the snapshot does not exist in Storage.
+	fallback Storage
+
+	pathResolver PathResolver
 }
 
 func (m *Matcher) Match(actual interface{}) (bool, error) {
+	recordSnapshot(m.Storage)
+
 	actualContent, err := m.getActualContent(actual)
 	if err != nil {
 		return false, fmt.Errorf("failed to get actual content: %w", err)
 	}
 
+	mode := m.getUpdateMode()
+
+	if mode == UpdateAll && !m.Review {
+		return m.writeSnapshot(actualContent)
+	}
+
 	expected, err := m.getExpectedContent()
 	if err != nil {
 		if !errors.Is(err, afero.ErrFileNotFound) {
 			return false, fmt.Errorf("failed to get expected content: %w", err)
 		}
 
-		if err := m.Storage.Write(actualContent); err != nil {
-			return false, fmt.Errorf("faield to write file: %w", err)
+		if mode == UpdateNone {
+			return false, fmt.Errorf("%w, run with UPDATE_GOLDEN=new to create it", ErrSnapshotNotFound)
+		}
+
+		if m.CI && !m.explicitUpdateMode && !m.UpdateFile {
+			return false, fmt.Errorf("%w, snapshots are not created in CI mode unless UPDATE_GOLDEN=new is set", ErrSnapshotNotFound)
+		}
+
+		if m.Review {
+			if err := m.writePendingSnapshot(actualContent); err != nil {
+				return false, err
+			}
+
+			return false, fmt.Errorf("%w, %s", ErrSnapshotPending, reviewHint)
 		}
 
+		return m.writeSnapshot(actualContent)
+	}
+
+	comparator := m.Comparator
+
+	if comparator == nil {
+		comparator = DefaultComparator
+	}
+
+	if ok, err := comparator.Compare(expected, actualContent); err != nil {
+		return false, fmt.Errorf("failed to compare content: %w", err)
+	} else if ok {
 		return true, nil
 	}
 
-	return bytes.Equal(expected, actualContent), nil
+	if m.Review {
+		return false, m.writePendingSnapshot(actualContent)
+	}
+
+	if mode == UpdateFailed {
+		return m.writeSnapshot(actualContent)
+	}
+
+	return false, nil
+}
+
+func (m *Matcher) getUpdateMode() UpdateMode {
+	if m.UpdateFile {
+		return UpdateAll
+	}
+
+	return m.UpdateMode
+}
+
+func (m *Matcher) writeSnapshot(content []byte) (bool, error) {
+	var err error
+
+	if s, ok := m.Storage.(MetadataStorage); ok && m.Metadata {
+		err = s.WriteWithMetadata(content, m.getMetadata())
+	} else {
+		err = m.Storage.Write(content)
+	}
+
+	if errors.Is(err, ErrReadOnly) {
+		return false, fmt.Errorf("failed to write snapshot in update mode %q: %w", m.getUpdateMode(), err)
+	}
+
+	if err != nil {
+		return false, fmt.Errorf("failed to write file: %w", err)
+	}
+
+	return true, nil
+}
+
+func (m *Matcher) getMetadata() *SnapshotMetadata {
+	metadata := &SnapshotMetadata{
+		Version: FormatVersion,
+	}
+	metadata.Serializer, metadata.SerializerConfig = describeValue(m.Serializer)
+	metadata.Transformer, _ = describeValue(m.Transformer)
+
+	if m.sourceFile != "" {
+		metadata.File = getSourcePath(m.sourceFile)
+		metadata.Line = m.sourceLine
+	}
+
+	return metadata
+}
+
+// getSerializerChange returns a message when the snapshot was created by
+// another serializer, in which case a diff is not helpful.
+func (m *Matcher) getSerializerChange() string {
+	s, ok := m.Storage.(MetadataStorage)
+	if !ok {
+		return ""
+	}
+
+	recorded, err := s.ReadMetadata()
+	if err != nil || recorded == nil || recorded.Serializer == "" {
+		return ""
+	}
+
+	current := m.getMetadata()
+
+	if recorded.Serializer == current.Serializer && recorded.SerializerConfig == current.SerializerConfig {
+		return ""
+	}
+
+	msg := fmt.Sprintf("serializer changed from %s to %s", recorded.Serializer, current.Serializer)
+
+	if recorded.Serializer == current.Serializer {
+		msg = fmt.Sprintf("config of %s changed from %s to %s", current.Serializer, recorded.SerializerConfig, current.SerializerConfig)
+	}
+
+	if recorded.File != "" {
+		msg += fmt.Sprintf("\nThe snapshot was created at %s:%d", recorded.File, recorded.Line)
+	}
+
+	return msg + "\nRun with UPDATE_GOLDEN=failed to update the snapshot"
+}
+
+func (m *Matcher) writePendingSnapshot(content []byte) error {
+	s, ok := m.Storage.(PendingStorage)
+	if !ok {
+		return fmt.Errorf("review mode is not supported by storage %T", m.Storage)
+	}
+
+	if err := s.WritePending(content); err != nil {
+		return fmt.Errorf("failed to write pending file: %w", err)
+	}
+
+	return nil
 }
 
 func (m *Matcher) getMessage(actual interface{}, message string) string {
+	if change := m.getSerializerChange(); change != "" {
+		return fmt.Sprintf("Expected %s match the golden file, but the %s", message, change)
+	}
+
 	expectedContent, err := m.getExpectedContent()
 	if err != nil {
 		panic(err)
@@ -118,17 +446,41 @@ func (m *Matcher) getMessage(actual interface{}, message string) string {
 		panic(err)
 	}
 
-	return fmt.Sprintf("Expected %s match the golden file\n%s",
+	if n, ok := m.Comparator.(Normalizer); ok {
+		expectedContent = normalizeContent(n, expectedContent)
+		actualContent = normalizeContent(n, actualContent)
+	}
+
+	msg := fmt.Sprintf("Expected %s match the golden file\n%s",
 		message,
-		m.Differ.Diff(expectedContent, actualContent))
+		m.getDiffer().Diff(expectedContent, actualContent))
+
+	if m.Review {
+		msg += "\n\nThe received snapshot is pending review, " + reviewHint
+	}
+
+	return msg
 }
 
-func (m *Matcher) getExpectedContent() ([]byte, error) {
-	if m.UpdateFile {
-		return nil, afero.ErrFileNotFound
+// normalizeContent returns the content as is when it cannot be normalized, so
+// the diff still shows why the content does not match.
+func normalizeContent(n Normalizer, data []byte) []byte {
+	if normalized, err := n.Normalize(data); err == nil {
+		return normalized
 	}
 
+	return data
+}
+
+func (m *Matcher) getExpectedContent() ([]byte, error) {
 	data, err := m.Storage.Read()
+
+	if errors.Is(err, afero.ErrFileNotFound) && m.fallback != nil {
+		if data, err = m.fallback.Read(); err == nil {
+			recordSnapshot(m.fallback)
+		}
+	}
+
 	if err != nil {
 		return nil, fmt.Errorf("failed to read file: %w", err)
 	}
@@ -147,7 +499,39 @@ func (m *Matcher) getActualContent(actual interface{}) ([]byte, error) {
 		return nil, fmt.Errorf("serialize error: %w", err)
 	}
 
-	return buf.Bytes(), nil
+	content := buf.Bytes()
+
+	for _, scrubber := range m.Scrubbers {
+		if content, err = scrubber.Scrub(content); err != nil {
+			return nil, fmt.Errorf("scrub error: %w", err)
+		}
+	}
+
+	if n, ok := m.Storage.(lineEndingNormalizer); ok {
+		content = n.normalizeLineEndings(content)
+	}
+
+	return content, nil
+}
+
+// getDiffer returns the differ. When the differ is a StructuredDiffer without
+// a format, the format is chosen by the serializer.
+func (m *Matcher) getDiffer() Differ {
+	d, ok := m.Differ.(*StructuredDiffer)
+	if !ok || d.Format != "" {
+		return m.Differ
+	}
+
+	differ := *d
+
+	switch m.Serializer.(type) {
+	case *JSONSerializer:
+		differ.Format = "json"
+	case *YAMLSerializer:
+		differ.Format = "yaml"
+	}
+
+	return &differ
 }
 
 func (m *Matcher) FailureMessage(actual interface{}) string {
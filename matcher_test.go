@@ -22,7 +22,7 @@ var _ = Describe("Matcher", func() {
 	)
 
 	BeforeEach(func() {
-		matcher = Match()
+		matcher = Match(WithCI(false), WithUpdateMode(UpdateNew), WithReview(false))
 		mockCtrl = gomock.NewController(GinkgoT())
 		storage = NewMockStorage(mockCtrl)
 		matcher.Storage = storage
@@ -97,6 +97,14 @@ var _ = Describe("Matcher", func() {
 	}
 
 	When("golden file exists", func() {
+		When("UpdateMode = all", func() {
+			BeforeEach(func() {
+				matcher.UpdateMode = UpdateAll
+			})
+
+			testUpdateFile()
+		})
+
 		When("UpdateFile = true", func() {
 			BeforeEach(func() {
 				matcher.UpdateFile = true
@@ -120,6 +128,14 @@ var _ = Describe("Matcher", func() {
 
 			testFail()
 
+			When("UpdateMode = failed", func() {
+				BeforeEach(func() {
+					matcher.UpdateMode = UpdateFailed
+				})
+
+				testUpdateFile()
+			})
+
 			Context("failure message", func() {
 				BeforeEach(func() {
 					storage.EXPECT().Read().Return([]byte{}, nil)
@@ -136,12 +152,77 @@ var _ = Describe("Matcher", func() {
 		})
 	})
 
+	When("comparator is set", func() {
+		BeforeEach(func() {
+			actual = `{"b":1,"a":2}`
+			matcher.Serializer = &StringSerializer{}
+			matcher.Comparator = &JSONComparator{}
+		})
+
+		When("content is semantically equal", func() {
+			BeforeEach(func() {
+				storage.EXPECT().Read().Return([]byte(`{"a":2,"b":1}`), nil)
+			})
+
+			testSucceed()
+		})
+
+		When("content is different", func() {
+			BeforeEach(func() {
+				storage.EXPECT().Read().Return([]byte(`{"a":1,"b":1}`), nil).AnyTimes()
+			})
+
+			testFail()
+
+			It("should show the normalized diff", func() {
+				Expect(matcher.FailureMessage(actual)).To(ContainSubstring("-  \"a\": 1,\n+  \"a\": 2,"))
+			})
+		})
+	})
+
 	When("golden file does not exist", func() {
 		BeforeEach(func() {
 			storage.EXPECT().Read().Return(nil, afero.ErrFileNotFound)
 		})
 
 		testUpdateFile()
+
+		When("CI = true", func() {
+			BeforeEach(func() {
+				matcher.CI = true
+				matcher.explicitUpdateMode = false
+			})
+
+			testError(MatchError(ErrSnapshotNotFound))
+
+			It("should suggest UPDATE_GOLDEN=new", func() {
+				Expect(err).To(MatchError(ContainSubstring("UPDATE_GOLDEN=new")))
+			})
+
+			When("update mode is set explicitly", func() {
+				BeforeEach(func() {
+					WithUpdateMode(UpdateNew)(matcher)
+				})
+
+				testUpdateFile()
+			})
+		})
+
+		When("UpdateMode = none", func() {
+			BeforeEach(func() {
+				matcher.UpdateMode = UpdateNone
+			})
+
+			testError(MatchError(ErrSnapshotNotFound))
+		})
+
+		When("UpdateMode = failed", func() {
+			BeforeEach(func() {
+				matcher.UpdateMode = UpdateFailed
+			})
+
+			testUpdateFile()
+		})
 	})
 
 	When("failed to read golden file", func() {
@@ -161,4 +242,109 @@ var _ = Describe("Options", func() {
 			Expect("foobar").To(Match(WithDescription("Third Gold File")))
 		})
 	})
+
+	Describe("WithKey", func() {
+		It("should override the key of the snapshot", func() {
+			Expect(Match(WithLayout(LayoutSuite), WithKey("custom key")).Storage.(*SuiteStorage).Name).To(Equal("custom key"))
+			Expect("foo").To(Match(WithKey("custom key")))
+		})
+	})
+
+	Describe("ID label", Label("goldga:id=labeled"), func() {
+		It("should replace the text with the ID", Label("goldga:id=spec"), func() {
+			Expect(Match(WithLayout(LayoutSuite)).Storage.(*SuiteStorage).Name).To(Equal("Options labeled spec 1"))
+		})
+
+		It("should use the text when the spec does not have an ID", func() {
+			Expect(Match(WithLayout(LayoutSuite)).Storage.(*SuiteStorage).Name).To(Equal("Options labeled should use the text when the spec does not have an ID 1"))
+		})
+	})
+
+	Describe("snapshot numbers", func() {
+		It("should number snapshots in a spec", func() {
+			Expect("foo").To(Match(WithLayout(LayoutSuite)))
+			Expect("bar").To(Match(WithLayout(LayoutSuite)))
+			Expect(Match(WithLayout(LayoutSuite)).Storage.(*SuiteStorage).Name).To(Equal("Options snapshot numbers should number snapshots in a spec 3"))
+		})
+
+		It("should not number snapshots with a key", func() {
+			Expect(Match(WithLayout(LayoutSuite), WithKey("foo")).Storage.(*SuiteStorage).Name).To(Equal("foo"))
+			Expect(Match(WithLayout(LayoutSuite), WithKey("foo")).Storage.(*SuiteStorage).Name).To(Equal("foo"))
+		})
+
+		attempts := 0
+
+		It("should reset numbers when a spec is retried", FlakeAttempts(2), func() {
+			attempts++
+			Expect(Match(WithLayout(LayoutSuite)).Storage.(*SuiteStorage).Name).To(Equal("Options snapshot numbers should reset numbers when a spec is retried 1"))
+
+			if attempts == 1 {
+				Fail("fail the first attempt")
+			}
+		})
+	})
+
+	Describe("WithBinary", func() {
+		It("should store snapshots as binary", func() {
+			matcher := Match(WithLayout(LayoutSuite), WithBinary())
+			Expect(matcher.Serializer).To(Equal(&StringSerializer{}))
+			Expect(matcher.Differ).To(Equal(&HexDiffer{}))
+			Expect(matcher.Storage.(*SuiteStorage).Binary).To(BeTrue())
+		})
+
+		It("should keep line endings in the dir layout", func() {
+			matcher := Match(WithLayout(LayoutDir), WithBinary())
+			storage := matcher.Storage.(*DirStorage)
+			Expect(storage.Ext).To(Equal(".bin"))
+			Expect(storage.KeepLineEndings).To(BeTrue())
+			Expect(matcher.getActualContent("a\r\nb")).To(Equal([]byte("a\r\nb")))
+		})
+	})
+
+	Describe("WithStructuredDiff", func() {
+		DescribeTable("should choose the format by the serializer", func(serializer Serializer, format string) {
+			matcher := Match(WithStructuredDiff(), WithSerializer(serializer))
+			Expect(matcher.getDiffer()).To(Equal(&StructuredDiffer{Format: format}))
+		},
+			Entry("json", &JSONSerializer{}, "json"),
+			Entry("yaml", &YAMLSerializer{}, "yaml"),
+			Entry("others", &StringSerializer{}, ""),
+		)
+
+		It("should keep the format set explicitly", func() {
+			matcher := Match(WithDiffer(&StructuredDiffer{Format: "yaml"}), WithSerializer(&JSONSerializer{}))
+			Expect(matcher.getDiffer()).To(Equal(&StructuredDiffer{Format: "yaml"}))
+		})
+
+		It("should show changes by paths", func() {
+			matcher := Match(WithStructuredDiff(), WithSerializer(&JSONSerializer{}), WithStorage(&SuiteStorage{
+				Path: "foo.golden",
+				Name: "Foo",
+				Fs:   afero.NewMemMapFs(),
+			}))
+			Expect(matcher.Storage.Write([]byte(`{"a":1,"b":2}`))).To(Succeed())
+			Expect(matcher.FailureMessage(map[string]int{"a": 1, "b": 3})).To(ContainSubstring("~ b: 2 → 3"))
+		})
+	})
+
+	Describe("WithComparator", func() {
+		It("should match semantically equal content", func() {
+			Expect(`{"b":1,"a":2}`).To(Match(
+				WithSerializer(&StringSerializer{}),
+				WithComparator(&JSONComparator{}),
+			))
+		})
+	})
+
+	Describe("WithRawLineEndings", func() {
+		It("should keep line endings", func() {
+			matcher := Match(WithLayout(LayoutSuite), WithRawLineEndings())
+			Expect(matcher.Storage.(*SuiteStorage).KeepLineEndings).To(BeTrue())
+		})
+	})
+
+	It("should convert line endings of the actual content", func() {
+		matcher := Match(WithSerializer(&StringSerializer{}))
+		Expect(matcher.getActualContent("a\r\nb")).To(Equal([]byte("a\nb")))
+	})
 })
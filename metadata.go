@@ -0,0 +1,171 @@
+package goldga
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+// SnapshotMetadata describes how a snapshot was created.
+type SnapshotMetadata struct {
+	Serializer       string `toml:"serializer"`
+	SerializerConfig string `toml:"serializer_config"`
+	Transformer      string `toml:"transformer"`
+	File             string `toml:"file"`
+	Line             int    `toml:"line"`
+	Version          int    `toml:"version"`
+}
+
+// MetadataStorage is implemented by storages which can store metadata next to
+// snapshots.
+type MetadataStorage interface {
+	// ReadMetadata returns nil when the snapshot does not have metadata.
+	ReadMetadata() (*SnapshotMetadata, error)
+	WriteWithMetadata(data []byte, metadata *SnapshotMetadata) error
+}
+
+var _ MetadataStorage = (*SuiteStorage)(nil)
+
+// ReadMetadata returns the metadata of the snapshot.
+func (s *SuiteStorage) ReadMetadata() (*SnapshotMetadata, error) {
+	data, err := s.getSuiteData()
+	if err != nil {
+		return nil, err
+	}
+
+	return data.Metadata[s.Name], nil
+}
+
+// WriteWithMetadata writes the snapshot and its metadata.
+func (s *SuiteStorage) WriteWithMetadata(input []byte, metadata *SnapshotMetadata) error {
+	w := &snapshotWrite{
+		Path:            s.Path,
+		Name:            s.Name,
+		Data:            s.normalizeLineEndings(input),
+		Binary:          s.Binary,
+		KeepLineEndings: s.KeepLineEndings,
+		Metadata:        metadata,
+	}
+
+	if s.Batch {
+		return s.getIndex().write(s.Fs, w)
+	}
+
+	return s.updateSuiteData(w.apply)
+}
+
+func getMetadataEnabled() bool {
+	enabled, _ := strconv.ParseBool(os.Getenv("GOLDGA_METADATA"))
+
+	return enabled
+}
+
+// describeValue returns the type of the value, and its fields when it is a
+// struct or a pointer to a struct.
+func describeValue(v interface{}) (string, string) {
+	if v == nil {
+		return "", ""
+	}
+
+	config := ""
+
+	if rv := reflect.Indirect(reflect.ValueOf(v)); rv.Kind() == reflect.Struct && rv.NumField() > 0 {
+		config = fmt.Sprintf("%+v", describeStruct(rv))
+	}
+
+	return fmt.Sprintf("%T", v), config
+}
+
+// describeStruct dereferences pointer fields, so the description does not
+// contain memory addresses.
+func describeStruct(v reflect.Value) map[string]interface{} {
+	result := map[string]interface{}{}
+
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+
+		if field.PkgPath != "" {
+			continue
+		}
+
+		value := v.Field(i)
+
+		for value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+			if value.IsNil() {
+				break
+			}
+
+			value = value.Elem()
+		}
+
+		switch {
+		case value.Kind() == reflect.Struct:
+			result[field.Name] = describeStruct(value)
+		case value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface:
+			result[field.Name] = nil
+		default:
+			result[field.Name] = value.Interface()
+		}
+	}
+
+	return result
+}
+
+// getSourcePath returns the path relative to the working directory, so the
+// metadata does not depend on the location of the repository.
+func getSourcePath(path string) string {
+	wd, err := os.Getwd()
+	if err != nil {
+		return path
+	}
+
+	if rel, err := filepath.Rel(wd, path); err == nil {
+		return filepath.ToSlash(rel)
+	}
+
+	return path
+}
+
+func writeMetadataEntries(w io.Writer, data *suiteData) error {
+	keys := make([]string, 0, len(data.Metadata))
+
+	for k, v := range data.Metadata {
+		if _, ok := data.Snapshots[k]; ok && v != nil {
+			keys = append(keys, k)
+		}
+	}
+
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		m := data.Metadata[k]
+
+		if _, err := fmt.Fprintf(w, "[metadata.%s]\n", quoteTOMLString(k)); err != nil {
+			return fmt.Errorf("metadata write error: %w", err)
+		}
+
+		fields := []struct {
+			key   string
+			value string
+		}{
+			{"serializer", quoteTOMLString(m.Serializer)},
+			{"serializer_config", quoteTOMLString(m.SerializerConfig)},
+			{"transformer", quoteTOMLString(m.Transformer)},
+			{"file", quoteTOMLString(m.File)},
+			{"line", strconv.Itoa(m.Line)},
+			{"version", strconv.Itoa(m.Version)},
+		}
+
+		for _, f := range fields {
+			if _, err := fmt.Fprintf(w, "%s = %s\n", f.key, f.value); err != nil {
+				return fmt.Errorf("metadata write error: %w", err)
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,124 @@
+package goldga
+
+import (
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/afero"
+)
+
+var _ = Describe("SnapshotMetadata", func() {
+	var (
+		fs      *tempFs
+		storage *SuiteStorage
+	)
+
+	BeforeEach(func() {
+		fs = newTempFs()
+		storage = &SuiteStorage{
+			Path: filepath.Join(fs.path, "foo.golden"),
+			Name: "Suite test",
+			Fs:   fs,
+		}
+	})
+
+	AfterEach(func() {
+		fs.Teardown()
+	})
+
+	readFile := func() string {
+		content, err := afero.ReadFile(fs, storage.Path)
+		Expect(err).NotTo(HaveOccurred())
+
+		return string(content)
+	}
+
+	newMetadataMatcher := func(options ...Option) *Matcher {
+		m := newMatcher(storage.Path, storage.Name, append([]Option{
+			WithStorage(storage),
+			WithUpdateMode(UpdateNew),
+			WithCI(false),
+			WithReview(false),
+		}, options...))
+		m.sourceFile, m.sourceLine = "foo_test.go", 12
+
+		return m
+	}
+
+	It("should write metadata next to the snapshot", func() {
+		metadata := &SnapshotMetadata{
+			Serializer: "*goldga.JSONSerializer",
+			File:       "foo_test.go",
+			Line:       12,
+			Version:    FormatVersion,
+		}
+		Expect(storage.WriteWithMetadata([]byte("foo"), metadata)).To(Succeed())
+		Expect(readFile()).To(HaveSuffix(`[metadata."Suite test"]
+serializer = "*goldga.JSONSerializer"
+serializer_config = ""
+transformer = ""
+file = "foo_test.go"
+line = 12
+version = 3
+`))
+		Expect(storage.ReadMetadata()).To(Equal(metadata))
+	})
+
+	It("should remove metadata when the snapshot is written without it", func() {
+		Expect(storage.WriteWithMetadata([]byte("foo"), &SnapshotMetadata{Serializer: "foo"})).To(Succeed())
+		Expect(storage.Write([]byte("bar"))).To(Succeed())
+		Expect(storage.ReadMetadata()).To(BeNil())
+		Expect(readFile()).NotTo(ContainSubstring("[metadata"))
+	})
+
+	It("should remove metadata when the snapshot is deleted", func() {
+		Expect(storage.WriteWithMetadata([]byte("foo"), &SnapshotMetadata{Serializer: "foo"})).To(Succeed())
+		Expect(storage.Delete(storage.Name)).To(Succeed())
+		Expect(readFile()).NotTo(ContainSubstring("[metadata"))
+	})
+
+	When("metadata is enabled", func() {
+		BeforeEach(func() {
+			Expect(newMetadataMatcher(WithMetadata(true)).Match("foo")).To(BeTrue())
+		})
+
+		It("should record the serializer and the location", func() {
+			metadata, err := storage.ReadMetadata()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(metadata.Serializer).To(Equal("*goldga.DumpSerializer"))
+			Expect(metadata.SerializerConfig).To(ContainSubstring("SortKeys:true"))
+			Expect(metadata.Transformer).To(Equal("*goldga.NopTransformer"))
+			Expect(metadata.File).To(Equal("foo_test.go"))
+			Expect(metadata.Line).To(Equal(12))
+			Expect(metadata.Version).To(Equal(FormatVersion))
+		})
+
+		It("should report a changed serializer instead of a diff", func() {
+			m := newMetadataMatcher(WithSerializer(&JSONSerializer{}))
+			Expect(m.Match("foo")).To(BeFalse())
+			Expect(m.FailureMessage("foo")).To(Equal(`Expected to match the golden file, but the serializer changed from *goldga.DumpSerializer to *goldga.JSONSerializer
+The snapshot was created at foo_test.go:12
+Run with UPDATE_GOLDEN=failed to update the snapshot`))
+		})
+
+		It("should report a changed serializer config", func() {
+			m := newMetadataMatcher(WithSerializer(&DumpSerializer{Config: newDefaultDumpConfig()}))
+			m.Serializer.(*DumpSerializer).Config.Indent = "\t"
+			Expect(m.FailureMessage("foo")).To(HavePrefix("Expected to match the golden file, but the config of *goldga.DumpSerializer changed"))
+		})
+	})
+})
+
+var _ = Describe("describeValue", func() {
+	It("should describe the type and fields", func() {
+		t, config := describeValue(&JSONSerializer{Indent: "  "})
+		Expect(t).To(Equal("*goldga.JSONSerializer"))
+		Expect(config).To(Equal(`map[EscapeHTML:false Indent:   IndentPrefix:]`))
+	})
+
+	It("should not describe nil pointers", func() {
+		_, config := describeValue(&StringSerializer{})
+		Expect(config).To(Equal(`map[FallbackSerializer:<nil>]`))
+	})
+})
@@ -0,0 +1,189 @@
+package goldga
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+
+	"github.com/onsi/ginkgo/v2/types"
+	"github.com/spf13/afero"
+)
+
+const snapshotReportEntryName = "goldga:snapshot"
+
+type snapshotRef struct {
+	Path string `json:"path"`
+	Name string `json:"name"`
+
+	// Layout is LayoutDir when Path is the snapshot file of DirStorage.
+	Layout Layout `json:"layout,omitempty"`
+}
+
+func getPruneObsolete() bool {
+	prune, _ := strconv.ParseBool(os.Getenv("GOLDGA_PRUNE"))
+
+	return prune
+}
+
+// recordSnapshot adds a hidden report entry to the current spec, so the
+// snapshot is known to be in use even when specs run in parallel processes.
+func recordSnapshot(storage Storage) {
+	// Snapshots of MatchT are tracked by the test instead.
+	if s, ok := storage.(*testStorage); ok {
+		testSnapshots.add(s.Fs, s.Path, s.Name)
+
+		return
+	}
+
+	if !isGinkgoSpecRunning() {
+		return
+	}
+
+	var ref snapshotRef
+
+	// Obsolete snapshots are checked in the default file system, so snapshots
+	// in other file systems, e.g. read-only or in-memory ones, are not recorded.
+	// Otherwise unrelated files on the disk with the same paths could be pruned.
+	switch s := storage.(type) {
+	case *SuiteStorage:
+		if s.Fs != defaultFs {
+			return
+		}
+
+		ref = snapshotRef{Path: s.Path, Name: s.Name}
+	case *DirStorage:
+		if s.Fs != defaultFs {
+			return
+		}
+
+		ref = snapshotRef{Path: s.Path(), Layout: LayoutDir}
+	default:
+		return
+	}
+
+	addReportEntry(snapshotReportEntryName, ref)
+}
+
+// canDetectObsoleteSnapshots returns true only when every spec in the suite
+// has run and passed. Otherwise snapshots of skipped or failed specs would be
+// reported as obsolete.
+func canDetectObsoleteSnapshots(report types.Report) bool {
+	if report.SuiteHasProgrammaticFocus {
+		return false
+	}
+
+	for _, spec := range report.SpecReports {
+		if spec.LeafNodeType.Is(types.NodeTypeIt) && !spec.State.Is(types.SpecStatePassed) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func collectSnapshotRefs(report types.Report) (map[string]map[string]bool, error) {
+	result := map[string]map[string]bool{}
+
+	for _, spec := range report.SpecReports {
+		for _, entry := range spec.ReportEntries {
+			if entry.Name != snapshotReportEntryName {
+				continue
+			}
+
+			var ref snapshotRef
+
+			if err := decodeReportEntry(entry, &ref); err != nil {
+				return nil, err
+			}
+
+			if ref.Layout == LayoutDir {
+				continue
+			}
+
+			if result[ref.Path] == nil {
+				result[ref.Path] = map[string]bool{}
+			}
+
+			result[ref.Path][ref.Name] = true
+		}
+	}
+
+	return result, nil
+}
+
+func checkObsoleteSnapshots(fs afero.Fs, report types.Report, w io.Writer, prune bool) error {
+	if !canDetectObsoleteSnapshots(report) {
+		return nil
+	}
+
+	refs, err := collectSnapshotRefs(report)
+	if err != nil {
+		return fmt.Errorf("failed to collect snapshots: %w", err)
+	}
+
+	paths := make([]string, 0, len(refs))
+
+	for path := range refs {
+		paths = append(paths, path)
+	}
+
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		storage := &SuiteStorage{Path: path, Fs: fs}
+
+		if err := handleObsoleteSnapshots(w, storage, refs[path], nil, prune); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// handleObsoleteSnapshots reports or removes snapshots in the file which are
+// not touched. When owns is not nil, only snapshots owned by it are checked.
+func handleObsoleteSnapshots(w io.Writer, storage *SuiteStorage, touched map[string]bool, owns func(key string) bool, prune bool) error {
+	keys, err := storage.Keys()
+	if err != nil {
+		if errors.Is(err, afero.ErrFileNotFound) {
+			return nil
+		}
+
+		return fmt.Errorf("failed to read snapshots: %w", err)
+	}
+
+	var obsolete []string
+
+	for _, key := range keys {
+		if !touched[key] && (owns == nil || owns(key)) {
+			obsolete = append(obsolete, key)
+		}
+	}
+
+	if len(obsolete) == 0 {
+		return nil
+	}
+
+	if prune {
+		if err := storage.Delete(obsolete...); err != nil {
+			return fmt.Errorf("failed to delete obsolete snapshots: %w", err)
+		}
+
+		fmt.Fprintf(w, "goldga: removed %d obsolete snapshot(s) from %s\n", len(obsolete), storage.Path)
+
+		return nil
+	}
+
+	fmt.Fprintf(w, "goldga: found %d obsolete snapshot(s) in %s\n", len(obsolete), storage.Path)
+
+	for _, key := range obsolete {
+		fmt.Fprintf(w, "  %q\n", key)
+	}
+
+	fmt.Fprintln(w, "Run with GOLDGA_PRUNE=1 to remove them.")
+
+	return nil
+}
@@ -0,0 +1,157 @@
+package goldga
+
+import (
+	"bytes"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	"github.com/onsi/ginkgo/v2/types"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/afero"
+)
+
+var _ = Describe("checkObsoleteSnapshots", func() {
+	var (
+		fs      *tempFs
+		storage *SuiteStorage
+		report  types.Report
+		prune   bool
+		output  bytes.Buffer
+		err     error
+	)
+
+	newSpecReport := func(state types.SpecState, names ...string) types.SpecReport {
+		spec := types.SpecReport{
+			LeafNodeType: types.NodeTypeIt,
+			State:        state,
+		}
+
+		for _, name := range names {
+			spec.ReportEntries = append(spec.ReportEntries, types.ReportEntry{
+				Name: snapshotReportEntryName,
+				Value: types.WrapEntryValue(snapshotRef{
+					Path: storage.Path,
+					Name: name,
+				}),
+			})
+		}
+
+		return spec
+	}
+
+	BeforeEach(func() {
+		fs = newTempFs()
+		storage = &SuiteStorage{
+			Path: filepath.Join(fs.path, "foo.golden"),
+			Fs:   fs,
+		}
+		prune = false
+		output.Reset()
+
+		for _, name := range []string{"A", "B", "C"} {
+			storage.Name = name
+			Expect(storage.Write([]byte(name))).To(Succeed())
+		}
+
+		report = types.Report{
+			SpecReports: types.SpecReports{
+				newSpecReport(types.SpecStatePassed, "A"),
+				newSpecReport(types.SpecStatePassed, "B"),
+			},
+		}
+	})
+
+	AfterEach(func() {
+		fs.Teardown()
+	})
+
+	JustBeforeEach(func() {
+		err = checkObsoleteSnapshots(fs, report, &output, prune)
+	})
+
+	getKeys := func() []string {
+		keys, err := storage.Keys()
+		Expect(err).NotTo(HaveOccurred())
+
+		return keys
+	}
+
+	When("prune = false", func() {
+		It("should not return error", func() {
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should report obsolete snapshots", func() {
+			Expect(output.String()).To(ContainSubstring("found 1 obsolete snapshot(s)"))
+			Expect(output.String()).To(ContainSubstring(`"C"`))
+		})
+
+		It("should keep the file", func() {
+			Expect(getKeys()).To(Equal([]string{"A", "B", "C"}))
+		})
+	})
+
+	When("prune = true", func() {
+		BeforeEach(func() {
+			prune = true
+		})
+
+		It("should not return error", func() {
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should remove obsolete snapshots", func() {
+			Expect(getKeys()).To(Equal([]string{"A", "B"}))
+		})
+	})
+
+	When("some specs did not pass", func() {
+		BeforeEach(func() {
+			prune = true
+			report.SpecReports = append(report.SpecReports, newSpecReport(types.SpecStateSkipped))
+		})
+
+		It("should not report anything", func() {
+			Expect(output.String()).To(BeEmpty())
+		})
+
+		It("should keep the file", func() {
+			Expect(getKeys()).To(Equal([]string{"A", "B", "C"}))
+		})
+	})
+
+	When("all snapshots are used", func() {
+		BeforeEach(func() {
+			report.SpecReports = append(report.SpecReports, newSpecReport(types.SpecStatePassed, "C"))
+		})
+
+		It("should not report anything", func() {
+			Expect(output.String()).To(BeEmpty())
+		})
+	})
+})
+
+var _ = Describe("recordSnapshot", func() {
+	getRefs := func() []snapshotRef {
+		var refs []snapshotRef
+
+		for _, entry := range CurrentSpecReport().ReportEntries {
+			if ref, ok := entry.GetRawValue().(snapshotRef); ok && entry.Name == snapshotReportEntryName {
+				refs = append(refs, ref)
+			}
+		}
+
+		return refs
+	}
+
+	It("should record snapshots in the default file system", func() {
+		recordSnapshot(&SuiteStorage{Path: "foo.golden", Name: "Foo", Fs: defaultFs})
+		Expect(getRefs()).To(ContainElement(snapshotRef{Path: "foo.golden", Name: "Foo"}))
+	})
+
+	It("should not record snapshots in other file systems", func() {
+		recordSnapshot(&SuiteStorage{Path: "foo.golden", Name: "Foo", Fs: afero.NewMemMapFs()})
+		recordSnapshot(&DirStorage{Dir: "foo", Name: "Foo", Fs: afero.NewMemMapFs()})
+		Expect(getRefs()).To(BeEmpty())
+	})
+})
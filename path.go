@@ -0,0 +1,151 @@
+package goldga
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// nolint: gochecknoglobals
+var (
+	DefaultPathResolver PathResolver = &TestdataPathResolver{}
+)
+
+// PathResolver returns the path of the golden file for a test file. The path
+// is relative to the working directory, which is the package directory when
+// running `go test`.
+type PathResolver interface {
+	Resolve(testFile string) (string, error)
+}
+
+var (
+	_ PathResolver = (*TestdataPathResolver)(nil)
+	_ PathResolver = (*MirrorPathResolver)(nil)
+	_ PathResolver = (*PackagePathResolver)(nil)
+	_ PathResolver = (*TemplatePathResolver)(nil)
+)
+
+// TestdataPathResolver stores golden files at "testdata/<file>.golden", in
+// which "_test" is removed from the file name. This is the default resolver.
+type TestdataPathResolver struct{}
+
+func (r *TestdataPathResolver) Resolve(testFile string) (string, error) {
+	return getGoldenPath(testFile), nil
+}
+
+// MirrorPathResolver stores golden files in Dir, mirroring the path of test
+// files relative to the working directory, so test files with the same name in
+// different directories do not share a golden file.
+type MirrorPathResolver struct {
+	// Dir defaults to "testdata".
+	Dir string
+}
+
+func (r *MirrorPathResolver) Resolve(testFile string) (string, error) {
+	dir := r.Dir
+
+	if dir == "" {
+		dir = testdataDirName
+	}
+
+	segments := []string{dir}
+
+	if rel := filepath.Dir(getSourcePath(testFile)); rel != "." {
+		for _, segment := range strings.Split(filepath.ToSlash(rel), "/") {
+			segments = append(segments, sanitizeFileName(segment))
+		}
+	}
+
+	return filepath.Join(append(segments, filepath.Base(getGoldenPath(testFile)))...), nil
+}
+
+// PackagePathResolver stores snapshots of all test files in a package in a
+// single golden file at "testdata/<name>.golden".
+type PackagePathResolver struct {
+	// Name defaults to the name of the directory of test files.
+	Name string
+}
+
+func (r *PackagePathResolver) Resolve(testFile string) (string, error) {
+	name := r.Name
+
+	if name == "" {
+		name = filepath.Base(filepath.Dir(testFile))
+	}
+
+	return filepath.Join(testdataDirName, sanitizeFileName(name)+GoldenFileExt), nil
+}
+
+// TemplatePathResolver resolves paths with a text/template. The template is
+// executed with the following fields:
+//
+//   - Dir: the directory of the test file relative to the working directory.
+//   - Package: the name of the directory of the test file.
+//   - File: the test file name without the extension and "_test".
+//
+// For example, "__snapshots__/{{.File}}.golden".
+type TemplatePathResolver struct {
+	Template string
+}
+
+type pathTemplateData struct {
+	Dir     string
+	Package string
+	File    string
+}
+
+func (r *TemplatePathResolver) Resolve(testFile string) (string, error) {
+	tmpl, err := template.New("path").Option("missingkey=error").Parse(r.Template)
+	if err != nil {
+		return "", fmt.Errorf("template parse error: %w", err)
+	}
+
+	var b strings.Builder
+
+	if err := tmpl.Execute(&b, pathTemplateData{
+		Dir:     filepath.Dir(getSourcePath(testFile)),
+		Package: filepath.Base(filepath.Dir(testFile)),
+		File:    strings.TrimSuffix(filepath.Base(getGoldenPath(testFile)), GoldenFileExt),
+	}); err != nil {
+		return "", fmt.Errorf("template execute error: %w", err)
+	}
+
+	return filepath.Clean(filepath.FromSlash(b.String())), nil
+}
+
+// resolveGoldenPath panics when the path cannot be resolved, because matchers
+// are created without returning errors.
+func resolveGoldenPath(resolver PathResolver, testFile string) string {
+	path, err := resolver.Resolve(testFile)
+	if err != nil {
+		panic(fmt.Errorf("failed to resolve the golden file path of %s: %w", testFile, err))
+	}
+
+	return path
+}
+
+// WithPath overrides the resolver of the golden file path. Unlike WithStorage,
+// other settings of the storage are kept.
+func WithPath(resolver PathResolver) Option {
+	return func(matcher *Matcher) {
+		matcher.pathResolver = resolver
+	}
+}
+
+// applyPathResolver moves the storage to the path resolved by the resolver set
+// by WithPath.
+func (m *Matcher) applyPathResolver(testFile string) {
+	if m.pathResolver == nil {
+		return
+	}
+
+	path := resolveGoldenPath(m.pathResolver, testFile)
+
+	switch s := m.Storage.(type) {
+	case *SuiteStorage:
+		s.Path = path
+	case *DirStorage:
+		s.Dir = strings.TrimSuffix(path, GoldenFileExt)
+	}
+}
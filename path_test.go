@@ -0,0 +1,58 @@
+package goldga
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("PathResolver", func() {
+	var wd string
+
+	BeforeEach(func() {
+		var err error
+		wd, err = os.Getwd()
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	DescribeTable("Resolve", func(resolver PathResolver, file, expected string) {
+		Expect(resolver.Resolve(filepath.Join(wd, filepath.FromSlash(file)))).To(Equal(filepath.FromSlash(expected)))
+	},
+		Entry("testdata", &TestdataPathResolver{}, "foo/bar_test.go", "testdata/bar.golden"),
+		Entry("mirror", &MirrorPathResolver{}, "foo/bar_test.go", "testdata/foo/bar.golden"),
+		Entry("mirror in working directory", &MirrorPathResolver{}, "bar_test.go", "testdata/bar.golden"),
+		Entry("mirror outside working directory", &MirrorPathResolver{}, "../foo/bar_test.go", "testdata/_/foo/bar.golden"),
+		Entry("mirror with dir", &MirrorPathResolver{Dir: "__snapshots__"}, "foo/bar_test.go", "__snapshots__/foo/bar.golden"),
+		Entry("package", &PackagePathResolver{}, "foo/bar_test.go", "testdata/foo.golden"),
+		Entry("package with name", &PackagePathResolver{Name: "snapshots"}, "foo/bar_test.go", "testdata/snapshots.golden"),
+		Entry("template", &TemplatePathResolver{Template: "__snapshots__/{{.File}}.golden"}, "bar_test.go", "__snapshots__/bar.golden"),
+		Entry("template with dir", &TemplatePathResolver{Template: "{{.Dir}}/__snapshots__/{{.Package}}_{{.File}}.golden"}, "foo/bar_test.go", "foo/__snapshots__/foo_bar.golden"),
+	)
+
+	It("should return error when the template is invalid", func() {
+		_, err := (&TemplatePathResolver{Template: "{{.Foo}}"}).Resolve("bar_test.go")
+		Expect(err).To(HaveOccurred())
+	})
+
+	Describe("WithPath", func() {
+		resolver := &TemplatePathResolver{Template: "__snapshots__/{{.File}}.golden"}
+
+		It("should keep settings of SuiteStorage", func() {
+			m := Match(WithLayout(LayoutSuite), WithBinary(), WithPath(resolver))
+			Expect(m.Storage).To(Equal(&SuiteStorage{
+				Path:   filepath.Join("__snapshots__", "path.golden"),
+				Name:   "PathResolver WithPath should keep settings of SuiteStorage 1",
+				Fs:     defaultFs,
+				Binary: true,
+				Batch:  true,
+			}))
+		})
+
+		It("should move DirStorage", func() {
+			m := Match(WithLayout(LayoutDir), WithPath(resolver))
+			Expect(m.Storage.(*DirStorage).Dir).To(Equal(filepath.Join("__snapshots__", "path")))
+		})
+	})
+})
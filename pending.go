@@ -0,0 +1,170 @@
+package goldga
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/afero"
+)
+
+const (
+	// GoldenFileExt is the extension of golden files created by SuiteStorage.
+	GoldenFileExt = ".golden"
+
+	// PendingFileSuffix is appended to the path of a golden file to get the
+	// path of its pending file.
+	PendingFileSuffix = ".new"
+)
+
+// PendingStorage is implemented by storages which support the review mode.
+type PendingStorage interface {
+	WritePending(data []byte) error
+}
+
+var _ PendingStorage = (*SuiteStorage)(nil)
+
+func (s *SuiteStorage) pendingStorage() *SuiteStorage {
+	return &SuiteStorage{
+		Path:            s.Path + PendingFileSuffix,
+		Name:            s.Name,
+		Fs:              s.Fs,
+		Binary:          s.Binary,
+		KeepLineEndings: s.KeepLineEndings,
+	}
+}
+
+// WritePending writes the snapshot to the pending file next to the golden file.
+func (s *SuiteStorage) WritePending(data []byte) error {
+	return s.pendingStorage().Write(data)
+}
+
+// PendingSnapshot is a snapshot waiting to be accepted or rejected.
+type PendingSnapshot struct {
+	// Path is the path of the golden file.
+	Path string
+	Name string
+	Fs   afero.Fs
+
+	// Binary is true when the pending snapshot is stored as binary, e.g.
+	// written with WithBinary.
+	Binary bool
+}
+
+// FindPendingSnapshots returns pending snapshots in the given directory and
+// its subdirectories.
+func FindPendingSnapshots(fs afero.Fs, root string) ([]*PendingSnapshot, error) {
+	var result []*PendingSnapshot
+
+	err := walkFiles(fs, root, func(path string) error {
+		if !strings.HasSuffix(path, GoldenFileExt+PendingFileSuffix) {
+			return nil
+		}
+
+		storage := &SuiteStorage{
+			Path: strings.TrimSuffix(path, PendingFileSuffix),
+			Fs:   fs,
+		}
+		data, err := storage.pendingStorage().getSuiteData()
+		if err != nil {
+			return fmt.Errorf("failed to read pending file %q: %w", path, err)
+		}
+
+		for _, key := range data.sortSnapshotKeys() {
+			result = append(result, &PendingSnapshot{
+				Path:   storage.Path,
+				Name:   key,
+				Fs:     fs,
+				Binary: data.isBinary(key),
+			})
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find pending snapshots: %w", err)
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Path < result[j].Path
+	})
+
+	return result, nil
+}
+
+// storage returns the storage of the golden file. Snapshots were normalized
+// when they were written, so they are moved as is.
+func (p *PendingSnapshot) storage() *SuiteStorage {
+	return &SuiteStorage{
+		Path:            p.Path,
+		Name:            p.Name,
+		Fs:              p.Fs,
+		KeepLineEndings: true,
+	}
+}
+
+// Read returns the current snapshot and the pending snapshot. The current
+// snapshot is nil when the snapshot is new.
+func (p *PendingSnapshot) Read() (current, pending []byte, err error) {
+	storage := p.storage()
+
+	if pending, err = storage.pendingStorage().Read(); err != nil {
+		return nil, nil, err
+	}
+
+	if current, err = storage.Read(); err != nil && !errors.Is(err, afero.ErrFileNotFound) {
+		return nil, nil, err
+	}
+
+	return current, pending, nil
+}
+
+// Accept writes the pending snapshot to the golden file.
+func (p *PendingSnapshot) Accept() error {
+	storage := p.storage()
+
+	data, err := storage.pendingStorage().getSuiteData()
+	if err != nil {
+		return err
+	}
+
+	snapshot, ok := data.Snapshots[p.Name]
+	if !ok {
+		return afero.ErrFileNotFound
+	}
+
+	// Keep binary snapshots in the [binary] section.
+	storage.Binary = data.isBinary(p.Name)
+
+	if err := storage.WriteWithMetadata([]byte(snapshot), data.Metadata[p.Name]); err != nil {
+		return err
+	}
+
+	return p.Reject()
+}
+
+// Reject removes the pending snapshot. The pending file is removed when it
+// does not contain any snapshots.
+func (p *PendingSnapshot) Reject() error {
+	storage := p.storage().pendingStorage()
+
+	if err := storage.Delete(p.Name); err != nil {
+		return err
+	}
+
+	keys, err := storage.Keys()
+	if err != nil {
+		return err
+	}
+
+	if len(keys) > 0 {
+		return nil
+	}
+
+	if err := storage.Fs.Remove(storage.Path); err != nil {
+		return fmt.Errorf("failed to remove pending file: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,212 @@
+package goldga
+
+import (
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/afero"
+)
+
+var _ = Describe("Review mode", func() {
+	var (
+		fs      *tempFs
+		storage *SuiteStorage
+		matcher *Matcher
+		success bool
+		err     error
+	)
+
+	BeforeEach(func() {
+		fs = newTempFs()
+		storage = &SuiteStorage{
+			Path: filepath.Join(fs.path, "testdata", "foo.golden"),
+			Name: "Review test",
+			Fs:   fs,
+		}
+		matcher = Match(
+			WithStorage(storage),
+			WithSerializer(&StringSerializer{}),
+			WithUpdateMode(UpdateNew),
+			WithCI(false),
+			WithReview(true),
+		)
+	})
+
+	AfterEach(func() {
+		fs.Teardown()
+	})
+
+	JustBeforeEach(func() {
+		success, err = matcher.Match("bar")
+	})
+
+	readPending := func() ([]byte, error) {
+		return storage.pendingStorage().Read()
+	}
+
+	When("snapshot does not exist", func() {
+		It("should fail", func() {
+			Expect(success).To(BeFalse())
+		})
+
+		It("should return pending error", func() {
+			Expect(err).To(MatchError(ErrSnapshotPending))
+		})
+
+		It("should write the pending file", func() {
+			Expect(readPending()).To(Equal([]byte("bar")))
+		})
+
+		It("should not write the golden file", func() {
+			Expect(afero.Exists(fs, storage.Path)).To(BeFalse())
+		})
+	})
+
+	When("snapshot does not match", func() {
+		BeforeEach(func() {
+			Expect(storage.Write([]byte("foo"))).To(Succeed())
+		})
+
+		It("should fail", func() {
+			Expect(success).To(BeFalse())
+		})
+
+		It("should not return error", func() {
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should write the pending file", func() {
+			Expect(readPending()).To(Equal([]byte("bar")))
+		})
+
+		It("should mention review in failure message", func() {
+			Expect(matcher.FailureMessage("bar")).To(ContainSubstring("pending review"))
+		})
+	})
+
+	When("snapshot matches", func() {
+		BeforeEach(func() {
+			Expect(storage.Write([]byte("bar"))).To(Succeed())
+		})
+
+		It("should succeed", func() {
+			Expect(success).To(BeTrue())
+		})
+
+		It("should not write the pending file", func() {
+			_, err := readPending()
+			Expect(err).To(Equal(afero.ErrFileNotFound))
+		})
+	})
+})
+
+var _ = Describe("PendingSnapshot", func() {
+	var (
+		fs        *tempFs
+		storage   *SuiteStorage
+		snapshots []*PendingSnapshot
+	)
+
+	BeforeEach(func() {
+		fs = newTempFs()
+		storage = &SuiteStorage{
+			Path: filepath.Join(fs.path, "testdata", "foo.golden"),
+			Name: "A",
+			Fs:   fs,
+		}
+
+		Expect(storage.Write([]byte("a"))).To(Succeed())
+		Expect(storage.WritePending([]byte("new a"))).To(Succeed())
+
+		storage.Name = "B"
+		Expect(storage.WritePending([]byte("new b"))).To(Succeed())
+
+		var err error
+		snapshots, err = FindPendingSnapshots(fs, fs.path)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		fs.Teardown()
+	})
+
+	readSnapshot := func(name string) string {
+		s := &SuiteStorage{Path: storage.Path, Name: name, Fs: fs}
+		data, err := s.Read()
+		Expect(err).NotTo(HaveOccurred())
+
+		return string(data)
+	}
+
+	It("should find pending snapshots", func() {
+		Expect(snapshots).To(Equal([]*PendingSnapshot{
+			{Path: storage.Path, Name: "A", Fs: fs},
+			{Path: storage.Path, Name: "B", Fs: fs},
+		}))
+	})
+
+	It("should find binary pending snapshots", func() {
+		storage.Binary = true
+		Expect(storage.WritePending([]byte("new b"))).To(Succeed())
+		Expect(FindPendingSnapshots(fs, fs.path)).To(ContainElement(&PendingSnapshot{
+			Path:   storage.Path,
+			Name:   "B",
+			Fs:     fs,
+			Binary: true,
+		}))
+	})
+
+	Describe("Read", func() {
+		It("should return current and pending snapshots", func() {
+			current, pending, err := snapshots[0].Read()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(current).To(Equal([]byte("a")))
+			Expect(pending).To(Equal([]byte("new a")))
+		})
+
+		It("should return nil when the snapshot is new", func() {
+			current, pending, err := snapshots[1].Read()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(current).To(BeNil())
+			Expect(pending).To(Equal([]byte("new b")))
+		})
+	})
+
+	Describe("Accept", func() {
+		BeforeEach(func() {
+			Expect(snapshots[0].Accept()).To(Succeed())
+		})
+
+		It("should write the golden file", func() {
+			Expect(readSnapshot("A")).To(Equal("new a"))
+		})
+
+		It("should remove the pending snapshot", func() {
+			Expect(storage.pendingStorage().Keys()).To(Equal([]string{"B"}))
+		})
+
+		It("should keep binary snapshots in binary", func() {
+			storage.Binary = true
+			Expect(storage.WritePending([]byte("new b"))).To(Succeed())
+			Expect(snapshots[1].Accept()).To(Succeed())
+			Expect(readSnapshot("B")).To(Equal("new b"))
+			Expect(afero.ReadFile(fs, storage.Path)).To(ContainSubstring("[binary]\n\"B\" = "))
+		})
+	})
+
+	Describe("Reject", func() {
+		BeforeEach(func() {
+			Expect(snapshots[0].Reject()).To(Succeed())
+		})
+
+		It("should keep the golden file", func() {
+			Expect(readSnapshot("A")).To(Equal("a"))
+		})
+
+		It("should remove the pending file when it is empty", func() {
+			Expect(snapshots[1].Reject()).To(Succeed())
+			Expect(afero.Exists(fs, storage.pendingStorage().Path)).To(BeFalse())
+		})
+	})
+})
@@ -0,0 +1,534 @@
+package goldga
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/onsi/gomega/types"
+)
+
+// WithProperties asserts the properties of the actual value with gomega
+// matchers and replaces them with placeholders before serialization. It is
+// useful for dynamic values such as timestamps and generated IDs.
+//
+// Keys are property paths such as "metadata.createdAt" or "items[*].id".
+// Struct fields are matched by their JSON names or field names, and fields of
+// embedded structs are promoted like encoding/json does.
+func WithProperties(properties map[string]types.GomegaMatcher) Option {
+	return func(matcher *Matcher) {
+		matcher.Transformer = &PropertyTransformer{
+			Properties:  properties,
+			Transformer: matcher.Transformer,
+		}
+	}
+}
+
+var _ Transformer = (*PropertyTransformer)(nil)
+
+// PropertyTransformer asserts properties with gomega matchers and replaces
+// them with "<matched: MatcherName>" placeholders. Containers along the path
+// of a property are copied, and only converted to maps and slices of
+// interface{} when the placeholder does not fit in the original type.
+type PropertyTransformer struct {
+	Properties map[string]types.GomegaMatcher
+
+	// Transformer is applied before properties are replaced. It is optional.
+	Transformer Transformer
+}
+
+type propertySegment struct {
+	field string
+	index int
+	all   bool
+}
+
+func (s propertySegment) isIndex() bool {
+	return s.field == ""
+}
+
+func (p *PropertyTransformer) Transform(input interface{}) (interface{}, error) {
+	var err error
+
+	if p.Transformer != nil {
+		if input, err = p.Transformer.Transform(input); err != nil {
+			return nil, err
+		}
+	}
+
+	paths := make([]string, 0, len(p.Properties))
+
+	for path := range p.Properties {
+		paths = append(paths, path)
+	}
+
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		segments, err := parsePropertyPath(path)
+		if err != nil {
+			return nil, err
+		}
+
+		if input, err = replaceProperty(reflect.ValueOf(input), segments, p.Properties[path]); err != nil {
+			return nil, fmt.Errorf("property %q: %w", path, err)
+		}
+	}
+
+	return input, nil
+}
+
+func parsePropertyPath(path string) ([]propertySegment, error) {
+	var segments []propertySegment
+
+	for _, part := range strings.Split(path, ".") {
+		field := part
+		index := ""
+
+		if i := strings.IndexByte(part, '['); i >= 0 {
+			field, index = part[:i], part[i:]
+		}
+
+		if part == "" {
+			return nil, fmt.Errorf("invalid property path %q", path)
+		}
+
+		if field != "" {
+			segments = append(segments, propertySegment{field: field})
+		}
+
+		for index != "" {
+			end := strings.IndexByte(index, ']')
+			if index[0] != '[' || end < 0 {
+				return nil, fmt.Errorf("invalid property path %q", path)
+			}
+
+			if s := index[1:end]; s == "*" {
+				segments = append(segments, propertySegment{all: true})
+			} else {
+				i, err := strconv.Atoi(s)
+				if err != nil {
+					return nil, fmt.Errorf("invalid index in property path %q", path)
+				}
+
+				segments = append(segments, propertySegment{index: i})
+			}
+
+			index = index[end+1:]
+		}
+	}
+
+	if len(segments) == 0 {
+		return nil, errors.New("property path is empty")
+	}
+
+	return segments, nil
+}
+
+func getMatcherName(matcher types.GomegaMatcher) string {
+	t := reflect.TypeOf(matcher)
+
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	return strings.TrimSuffix(t.Name(), "Matcher")
+}
+
+func replaceProperty(v reflect.Value, segments []propertySegment, matcher types.GomegaMatcher) (interface{}, error) {
+	if len(segments) == 0 {
+		var actual interface{}
+
+		if v.IsValid() {
+			actual = v.Interface()
+		}
+
+		success, err := matcher.Match(actual)
+		if err != nil {
+			return nil, err
+		}
+
+		if !success {
+			return nil, errors.New(matcher.FailureMessage(actual))
+		}
+
+		return fmt.Sprintf("<matched: %s>", getMatcherName(matcher)), nil
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return nil, errors.New("nil value")
+		}
+
+		result, err := replaceProperty(v.Elem(), segments, matcher)
+		if err != nil {
+			return nil, err
+		}
+
+		// Keep the pointer when the type of the value is kept.
+		if rv := reflect.ValueOf(result); rv.IsValid() && rv.Type() == v.Type().Elem() {
+			ptr := reflect.New(rv.Type())
+			ptr.Elem().Set(rv)
+
+			return ptr.Interface(), nil
+		}
+
+		return result, nil
+
+	case reflect.Interface:
+		if v.IsNil() {
+			return nil, errors.New("nil value")
+		}
+
+		return replaceProperty(v.Elem(), segments, matcher)
+
+	case reflect.Invalid:
+		return nil, errors.New("nil value")
+	}
+
+	if segments[0].isIndex() {
+		return replaceIndexProperty(v, segments, matcher)
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		return replaceStructProperty(v, segments, matcher)
+	case reflect.Map:
+		return replaceMapProperty(v, segments, matcher)
+	}
+
+	return nil, fmt.Errorf("%s does not have field %q", v.Type(), segments[0].field)
+}
+
+// toAssignableValue returns the value when it can be assigned to a value of
+// type t, so only the property is replaced and the type of the container is
+// kept.
+func toAssignableValue(value interface{}, t reflect.Type) (reflect.Value, bool) {
+	if value == nil {
+		switch t.Kind() {
+		case reflect.Interface, reflect.Ptr, reflect.Map, reflect.Slice:
+			return reflect.Zero(t), true
+		}
+
+		return reflect.Value{}, false
+	}
+
+	rv := reflect.ValueOf(value)
+
+	return rv, rv.Type().AssignableTo(t)
+}
+
+func replaceIndexProperty(v reflect.Value, segments []propertySegment, matcher types.GomegaMatcher) (interface{}, error) {
+	seg, rest := segments[0], segments[1:]
+
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return nil, fmt.Errorf("%s is not a slice", v.Type())
+	}
+
+	if !seg.all && (seg.index < 0 || seg.index >= v.Len()) {
+		return nil, fmt.Errorf("index %d out of range", seg.index)
+	}
+
+	replaced := map[int]interface{}{}
+	keepType := true
+
+	for i := 0; i < v.Len(); i++ {
+		if !seg.all && i != seg.index {
+			continue
+		}
+
+		value, err := replaceProperty(v.Index(i), rest, matcher)
+		if err != nil {
+			return nil, fmt.Errorf("[%d]: %w", i, err)
+		}
+
+		replaced[i] = value
+
+		if _, ok := toAssignableValue(value, v.Type().Elem()); !ok {
+			keepType = false
+		}
+	}
+
+	if keepType {
+		var result reflect.Value
+
+		if v.Kind() == reflect.Slice {
+			result = reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+			reflect.Copy(result, v)
+		} else {
+			result = reflect.New(v.Type()).Elem()
+			result.Set(v)
+		}
+
+		for i, value := range replaced {
+			rv, _ := toAssignableValue(value, v.Type().Elem())
+			result.Index(i).Set(rv)
+		}
+
+		return result.Interface(), nil
+	}
+
+	result := make([]interface{}, v.Len())
+
+	for i := range result {
+		if value, ok := replaced[i]; ok {
+			result[i] = value
+		} else {
+			result[i] = v.Index(i).Interface()
+		}
+	}
+
+	return result, nil
+}
+
+func replaceStructProperty(v reflect.Value, segments []propertySegment, matcher types.GomegaMatcher) (interface{}, error) {
+	seg, rest := segments[0], segments[1:]
+
+	var (
+		field      structField
+		fieldValue reflect.Value
+		found      bool
+	)
+
+	for _, f := range getStructFields(v.Type()) {
+		if f.name != seg.field {
+			continue
+		}
+
+		if fieldValue, found = getFieldByIndex(v, f.index); found {
+			field = f
+		}
+
+		break
+	}
+
+	if !found {
+		return nil, fmt.Errorf("field %q not found", seg.field)
+	}
+
+	value, err := replaceProperty(fieldValue, rest, matcher)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", seg.field, err)
+	}
+
+	// Copy the struct and replace the field when the value fits in the field,
+	// and the field is not promoted through an embedded pointer.
+	if rv, ok := toAssignableValue(value, fieldValue.Type()); ok && !field.indirect {
+		result := reflect.New(v.Type()).Elem()
+		result.Set(v)
+
+		if target := result.FieldByIndex(field.index); target.CanSet() {
+			target.Set(rv)
+
+			return result.Interface(), nil
+		}
+	}
+
+	result := structToMap(v)
+	result[seg.field] = value
+
+	return result, nil
+}
+
+func replaceMapProperty(v reflect.Value, segments []propertySegment, matcher types.GomegaMatcher) (interface{}, error) {
+	seg, rest := segments[0], segments[1:]
+
+	var key reflect.Value
+
+	iter := v.MapRange()
+
+	for iter.Next() {
+		if fmt.Sprint(iter.Key().Interface()) == seg.field {
+			key = iter.Key()
+
+			break
+		}
+	}
+
+	if !key.IsValid() {
+		return nil, fmt.Errorf("field %q not found", seg.field)
+	}
+
+	value, err := replaceProperty(v.MapIndex(key), rest, matcher)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", seg.field, err)
+	}
+
+	if rv, ok := toAssignableValue(value, v.Type().Elem()); ok {
+		result := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+
+		for iter.Next() {
+			result.SetMapIndex(iter.Key(), iter.Value())
+		}
+
+		result.SetMapIndex(key, rv)
+
+		return result.Interface(), nil
+	}
+
+	result := make(map[string]interface{}, v.Len())
+	iter = v.MapRange()
+
+	for iter.Next() {
+		result[fmt.Sprint(iter.Key().Interface())] = iter.Value().Interface()
+	}
+
+	result[seg.field] = value
+
+	return result, nil
+}
+
+type structField struct {
+	name      string
+	index     []int
+	omitEmpty bool
+
+	// indirect is true when the field is promoted through an embedded pointer.
+	indirect bool
+}
+
+// getStructFields returns fields of a struct in the way encoding/json does.
+// Keys are JSON names when a json tag is set, otherwise field names. Fields of
+// embedded structs without a json name are promoted, and fields at a shallower
+// depth take precedence.
+func getStructFields(t reflect.Type) []structField {
+	var fields []structField
+
+	depths := map[string]int{}
+
+	var walk func(t reflect.Type, index []int, depth int, indirect bool)
+
+	walk = func(t reflect.Type, index []int, depth int, indirect bool) {
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			tag := strings.Split(field.Tag.Get("json"), ",")
+			name := tag[0]
+
+			if field.Tag.Get("json") == "-" {
+				continue
+			}
+
+			fieldIndex := append(append([]int(nil), index...), i)
+
+			if field.Anonymous && name == "" {
+				ft := field.Type
+				ptr := ft.Kind() == reflect.Ptr
+
+				if ptr {
+					ft = ft.Elem()
+				}
+
+				if ft.Kind() == reflect.Struct {
+					walk(ft, fieldIndex, depth+1, indirect || ptr)
+
+					continue
+				}
+			}
+
+			if field.PkgPath != "" {
+				continue
+			}
+
+			if name == "" {
+				name = field.Name
+			}
+
+			if d, ok := depths[name]; ok && d <= depth {
+				continue
+			}
+
+			depths[name] = depth
+			fields = append(fields, structField{
+				name:      name,
+				index:     fieldIndex,
+				omitEmpty: hasTagOption(tag[1:], "omitempty"),
+				indirect:  indirect,
+			})
+		}
+	}
+
+	walk(t, nil, 0, false)
+
+	// Remove fields shadowed by fields at a shallower depth found later.
+	result := fields[:0]
+
+	for _, f := range fields {
+		if depths[f.name] == len(f.index)-1 {
+			result = append(result, f)
+		}
+	}
+
+	return result
+}
+
+func hasTagOption(options []string, option string) bool {
+	for _, o := range options {
+		if o == option {
+			return true
+		}
+	}
+
+	return false
+}
+
+// getFieldByIndex returns the nested field. It returns false when an embedded
+// pointer along the way is nil.
+func getFieldByIndex(v reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+
+			v = v.Elem()
+		}
+
+		v = v.Field(x)
+	}
+
+	return v, true
+}
+
+// structToMap converts fields of a struct returned by getStructFields to a
+// map. Empty fields with the omitempty option are skipped.
+func structToMap(v reflect.Value) map[string]interface{} {
+	fields := getStructFields(v.Type())
+	result := make(map[string]interface{}, len(fields))
+
+	for _, f := range fields {
+		value, ok := getFieldByIndex(v, f.index)
+		if !ok || (f.omitEmpty && isEmptyValue(value)) {
+			continue
+		}
+
+		result[f.name] = value.Interface()
+	}
+
+	return result
+}
+
+// isEmptyValue reports whether the value is empty as defined by the omitempty
+// option of encoding/json.
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Interface, reflect.Ptr:
+		return v.IsNil()
+	}
+
+	return false
+}
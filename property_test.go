@@ -0,0 +1,205 @@
+package goldga
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/types"
+)
+
+type propertyTestItem struct {
+	ID   string `json:"id"`
+	Name string
+}
+
+type propertyTestData struct {
+	CreatedAt time.Time           `json:"createdAt"`
+	Items     []*propertyTestItem `json:"items"`
+	Labels    map[string]string   `json:"labels"`
+	hidden    string
+	Ignored   string `json:"-"`
+}
+
+type propertyTestBase struct {
+	ID        int       `json:"id"`
+	CreatedAt time.Time `json:"createdAt"`
+	Token     string    `json:"token,omitempty"`
+}
+
+type propertyTestEmbedded struct {
+	propertyTestBase
+	Name  string             `json:"name,omitempty"`
+	Items []propertyTestBase `json:"items,omitempty"`
+}
+
+var _ = Describe("PropertyTransformer", func() {
+	var (
+		transformer *PropertyTransformer
+		input       interface{}
+		output      interface{}
+		err         error
+	)
+
+	BeforeEach(func() {
+		input = &propertyTestData{
+			CreatedAt: time.Now(),
+			Items: []*propertyTestItem{
+				{ID: "1", Name: "a"},
+				{ID: "2", Name: "b"},
+			},
+			Labels: map[string]string{"foo": "bar"},
+			hidden: "hidden",
+		}
+		transformer = &PropertyTransformer{
+			Properties: map[string]types.GomegaMatcher{
+				"createdAt":   BeTemporally("~", time.Now(), time.Minute),
+				"items[*].id": Not(BeEmpty()),
+				"labels.foo":  Equal("bar"),
+			},
+		}
+	})
+
+	JustBeforeEach(func() {
+		output, err = transformer.Transform(input)
+	})
+
+	It("should not return error", func() {
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should replace properties with placeholders", func() {
+		Expect(output).To(Equal(map[string]interface{}{
+			"createdAt": "<matched: BeTemporally>",
+			"items": []*propertyTestItem{
+				{ID: "<matched: Not>", Name: "a"},
+				{ID: "<matched: Not>", Name: "b"},
+			},
+			"labels": map[string]string{"foo": "<matched: Equal>"},
+		}))
+	})
+
+	It("should not modify the input", func() {
+		Expect(input.(*propertyTestData).Items[0].ID).To(Equal("1"))
+	})
+
+	When("index is specified", func() {
+		BeforeEach(func() {
+			transformer.Properties = map[string]types.GomegaMatcher{
+				"items[1].id": Equal("2"),
+			}
+		})
+
+		It("should only replace the element", func() {
+			items := output.(*propertyTestData).Items
+			Expect(items[0]).To(BeIdenticalTo(input.(*propertyTestData).Items[0]))
+			Expect(items[1]).To(Equal(&propertyTestItem{ID: "<matched: Equal>", Name: "b"}))
+		})
+	})
+
+	When("the placeholder does not fit in the field", func() {
+		BeforeEach(func() {
+			input = &propertyTestEmbedded{
+				propertyTestBase: propertyTestBase{ID: 1},
+				Items:            []propertyTestBase{{ID: 2}},
+			}
+			transformer.Properties = map[string]types.GomegaMatcher{
+				"items[*].id": BeNumerically(">", 0),
+			}
+		})
+
+		It("should only convert containers along the path", func() {
+			Expect(output).To(Equal(map[string]interface{}{
+				"id":        1,
+				"createdAt": time.Time{},
+				"items": []interface{}{
+					map[string]interface{}{
+						"id":        "<matched: BeNumerically>",
+						"createdAt": time.Time{},
+					},
+				},
+			}))
+		})
+	})
+
+	When("struct has embedded fields", func() {
+		BeforeEach(func() {
+			input = propertyTestEmbedded{
+				propertyTestBase: propertyTestBase{ID: 1, CreatedAt: time.Now()},
+				Name:             "foo",
+			}
+			transformer.Properties = map[string]types.GomegaMatcher{
+				"createdAt": BeTemporally("~", time.Now(), time.Minute),
+			}
+		})
+
+		It("should flatten embedded fields and omit empty fields", func() {
+			Expect(output).To(Equal(map[string]interface{}{
+				"id":        1,
+				"createdAt": "<matched: BeTemporally>",
+				"name":      "foo",
+			}))
+		})
+	})
+
+	When("field of embedded struct fits the placeholder", func() {
+		BeforeEach(func() {
+			input = propertyTestEmbedded{
+				propertyTestBase: propertyTestBase{ID: 1, Token: "secret"},
+			}
+			transformer.Properties = map[string]types.GomegaMatcher{
+				"token": Equal("secret"),
+			}
+		})
+
+		It("should keep the struct", func() {
+			Expect(output).To(Equal(propertyTestEmbedded{
+				propertyTestBase: propertyTestBase{ID: 1, Token: "<matched: Equal>"},
+			}))
+		})
+	})
+
+	When("matcher fails", func() {
+		BeforeEach(func() {
+			transformer.Properties = map[string]types.GomegaMatcher{
+				"labels.foo": Equal("baz"),
+			}
+		})
+
+		It("should return error", func() {
+			Expect(err).To(MatchError(ContainSubstring(`property "labels.foo"`)))
+		})
+	})
+
+	DescribeTable("invalid paths", func(path string) {
+		transformer.Properties = map[string]types.GomegaMatcher{
+			path: BeNil(),
+		}
+		_, err := transformer.Transform(input)
+		Expect(err).To(HaveOccurred())
+	},
+		Entry("empty", ""),
+		Entry("empty segment", "items..id"),
+		Entry("invalid index", "items[a]"),
+		Entry("unclosed index", "items[0"),
+		Entry("out of range", "items[2].id"),
+		Entry("field not found", "foo"),
+		Entry("unexported field", "hidden"),
+		Entry("index on struct", "labels[0]"),
+		Entry("ignored field", "ignored"),
+	)
+
+	When("used with Match", func() {
+		It("should replace properties before serialization", func() {
+			Expect(map[string]interface{}{
+				"id":   "generated-id",
+				"name": "foo",
+			}).To(Match(
+				WithSerializer(&JSONSerializer{}),
+				WithProperties(map[string]types.GomegaMatcher{
+					"id": HavePrefix("generated-"),
+				}),
+			))
+		})
+	})
+})
@@ -0,0 +1,216 @@
+package goldga
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/afero"
+)
+
+// ErrReadOnly is returned when writing to a read-only file system.
+var ErrReadOnly = errors.New("golden files are read-only")
+
+var errUnsupported = errors.New("operation not supported")
+
+var (
+	_ afero.Fs   = (*ReadOnlyFs)(nil)
+	_ afero.File = (*readOnlyFile)(nil)
+)
+
+// ReadOnlyFs adapts an fs.FS, e.g. embed.FS, to afero.Fs, so it can be used
+// as Fs of SingleStorage, SuiteStorage and DirStorage. Every write returns
+// ErrReadOnly.
+type ReadOnlyFs struct {
+	FS fs.FS
+}
+
+// NewReadOnlyFs returns a read-only afero.Fs backed by the given fs.FS.
+func NewReadOnlyFs(fsys fs.FS) *ReadOnlyFs {
+	return &ReadOnlyFs{FS: fsys}
+}
+
+// toFSPath converts a file path to the slash-separated and unrooted path
+// required by fs.FS.
+func toFSPath(op, name string) (string, error) {
+	p := strings.TrimPrefix(path.Clean(filepath.ToSlash(name)), "/")
+
+	if p == "" {
+		p = "."
+	}
+
+	if !fs.ValidPath(p) {
+		return "", &os.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+
+	return p, nil
+}
+
+func readOnlyError(op, name string) error {
+	return &os.PathError{Op: op, Path: name, Err: ErrReadOnly}
+}
+
+func (r *ReadOnlyFs) Name() string {
+	return "ReadOnlyFs"
+}
+
+func (r *ReadOnlyFs) Open(name string) (afero.File, error) {
+	p, err := toFSPath("open", name)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := r.FS.Open(p)
+	if err != nil {
+		return nil, err
+	}
+
+	return &readOnlyFile{name: name, file: file}, nil
+}
+
+func (r *ReadOnlyFs) OpenFile(name string, flag int, perm os.FileMode) (afero.File, error) {
+	if flag&(os.O_WRONLY|os.O_RDWR|os.O_APPEND|os.O_CREATE|os.O_TRUNC) != 0 {
+		return nil, readOnlyError("open", name)
+	}
+
+	return r.Open(name)
+}
+
+func (r *ReadOnlyFs) Stat(name string) (os.FileInfo, error) {
+	p, err := toFSPath("stat", name)
+	if err != nil {
+		return nil, err
+	}
+
+	return fs.Stat(r.FS, p)
+}
+
+func (r *ReadOnlyFs) Create(name string) (afero.File, error) {
+	return nil, readOnlyError("create", name)
+}
+
+func (r *ReadOnlyFs) Mkdir(name string, perm os.FileMode) error {
+	return readOnlyError("mkdir", name)
+}
+
+func (r *ReadOnlyFs) MkdirAll(path string, perm os.FileMode) error {
+	return readOnlyError("mkdir", path)
+}
+
+func (r *ReadOnlyFs) Remove(name string) error {
+	return readOnlyError("remove", name)
+}
+
+func (r *ReadOnlyFs) RemoveAll(path string) error {
+	return readOnlyError("remove", path)
+}
+
+func (r *ReadOnlyFs) Rename(oldname, newname string) error {
+	return readOnlyError("rename", oldname)
+}
+
+func (r *ReadOnlyFs) Chmod(name string, mode os.FileMode) error {
+	return readOnlyError("chmod", name)
+}
+
+func (r *ReadOnlyFs) Chown(name string, uid, gid int) error {
+	return readOnlyError("chown", name)
+}
+
+func (r *ReadOnlyFs) Chtimes(name string, atime, mtime time.Time) error {
+	return readOnlyError("chtimes", name)
+}
+
+type readOnlyFile struct {
+	name string
+	file fs.File
+}
+
+func (f *readOnlyFile) Name() string {
+	return f.name
+}
+
+func (f *readOnlyFile) Close() error {
+	return f.file.Close()
+}
+
+func (f *readOnlyFile) Read(p []byte) (int, error) {
+	return f.file.Read(p)
+}
+
+func (f *readOnlyFile) ReadAt(p []byte, off int64) (int, error) {
+	if r, ok := f.file.(io.ReaderAt); ok {
+		return r.ReadAt(p, off)
+	}
+
+	return 0, &os.PathError{Op: "read", Path: f.name, Err: errUnsupported}
+}
+
+func (f *readOnlyFile) Seek(offset int64, whence int) (int64, error) {
+	if s, ok := f.file.(io.Seeker); ok {
+		return s.Seek(offset, whence)
+	}
+
+	return 0, &os.PathError{Op: "seek", Path: f.name, Err: errUnsupported}
+}
+
+func (f *readOnlyFile) Stat() (os.FileInfo, error) {
+	return f.file.Stat()
+}
+
+func (f *readOnlyFile) Readdir(count int) ([]os.FileInfo, error) {
+	dir, ok := f.file.(fs.ReadDirFile)
+	if !ok {
+		return nil, &os.PathError{Op: "readdir", Path: f.name, Err: errUnsupported}
+	}
+
+	entries, err := dir.ReadDir(count)
+	infos := make([]os.FileInfo, 0, len(entries))
+
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil {
+			return infos, err
+		}
+
+		infos = append(infos, info)
+	}
+
+	return infos, err
+}
+
+func (f *readOnlyFile) Readdirnames(n int) ([]string, error) {
+	infos, err := f.Readdir(n)
+	names := make([]string, len(infos))
+
+	for i, info := range infos {
+		names[i] = info.Name()
+	}
+
+	return names, err
+}
+
+func (f *readOnlyFile) Sync() error {
+	return nil
+}
+
+func (f *readOnlyFile) Write(p []byte) (int, error) {
+	return 0, readOnlyError("write", f.name)
+}
+
+func (f *readOnlyFile) WriteAt(p []byte, off int64) (int, error) {
+	return 0, readOnlyError("write", f.name)
+}
+
+func (f *readOnlyFile) WriteString(s string) (int, error) {
+	return 0, readOnlyError("write", f.name)
+}
+
+func (f *readOnlyFile) Truncate(size int64) error {
+	return readOnlyError("truncate", f.name)
+}
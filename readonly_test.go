@@ -0,0 +1,90 @@
+package goldga
+
+import (
+	"testing/fstest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/afero"
+)
+
+var _ = Describe("ReadOnlyFs", func() {
+	var fs *ReadOnlyFs
+
+	BeforeEach(func() {
+		fs = NewReadOnlyFs(fstest.MapFS{
+			"testdata/foo.golden": &fstest.MapFile{
+				Data: []byte("version = 2\n[snapshots]\n\"Foo\" = '''\nfoo'''\n"),
+			},
+			"testdata/bar.txt": &fstest.MapFile{
+				Data: []byte("bar"),
+			},
+		})
+	})
+
+	It("should read snapshots with SuiteStorage", func() {
+		storage := &SuiteStorage{Path: "testdata/foo.golden", Name: "Foo", Fs: fs}
+		Expect(storage.Read()).To(Equal([]byte("foo")))
+		Expect(storage.Keys()).To(Equal([]string{"Foo"}))
+	})
+
+	It("should read snapshots with SingleStorage", func() {
+		storage := &SingleStorage{Path: "testdata/bar.txt", Fs: fs}
+		Expect(storage.Read()).To(Equal([]byte("bar")))
+	})
+
+	It("should return not found error", func() {
+		storage := &SuiteStorage{Path: "testdata/foo.golden", Name: "Bar", Fs: fs}
+		_, err := storage.Read()
+		Expect(err).To(MatchError(afero.ErrFileNotFound))
+
+		_, err = (&SingleStorage{Path: "testdata/baz.txt", Fs: fs}).Read()
+		Expect(err).To(MatchError(afero.ErrFileNotFound))
+	})
+
+	It("should return ErrReadOnly on write", func() {
+		storage := &SuiteStorage{Path: "testdata/foo.golden", Name: "Foo", Fs: fs}
+		Expect(storage.Write([]byte("bar"))).To(MatchError(ErrReadOnly))
+		Expect(storage.Read()).To(Equal([]byte("foo")))
+
+		Expect((&SingleStorage{Path: "testdata/bar.txt", Fs: fs}).Write([]byte("baz"))).To(MatchError(ErrReadOnly))
+	})
+
+	It("should find golden files", func() {
+		Expect(FindGoldenFiles(fs, ".")).To(Equal([]string{"testdata/foo.golden"}))
+	})
+
+	It("should accept paths which are not unrooted", func() {
+		Expect(afero.ReadFile(fs, "./testdata/../testdata/bar.txt")).To(Equal([]byte("bar")))
+		Expect(afero.ReadFile(fs, "/testdata/bar.txt")).To(Equal([]byte("bar")))
+	})
+
+	When("used by a matcher", func() {
+		var matcher *Matcher
+
+		BeforeEach(func() {
+			matcher = newMatcher("", "", []Option{
+				WithStorage(&SuiteStorage{Path: "testdata/foo.golden", Name: "Foo", Fs: fs}),
+				WithSerializer(&StringSerializer{}),
+				WithUpdateMode(UpdateNew),
+				WithReview(false),
+				WithCI(false),
+			})
+		})
+
+		It("should match the snapshot", func() {
+			Expect(matcher.Match("foo")).To(BeTrue())
+		})
+
+		It("should fail when the snapshot does not match", func() {
+			Expect(matcher.Match("bar")).To(BeFalse())
+		})
+
+		It("should return error in update mode", func() {
+			matcher.UpdateMode = UpdateAll
+			_, err := matcher.Match("bar")
+			Expect(err).To(MatchError(ErrReadOnly))
+			Expect(err.Error()).To(ContainSubstring(`update mode "all"`))
+		})
+	})
+})
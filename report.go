@@ -0,0 +1,57 @@
+package goldga
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/ginkgo/v2/types"
+)
+
+// nolint: gochecknoglobals
+var _ = ginkgo.ReportAfterSuite("goldga", reportAfterSuite)
+
+func isGinkgoSpecRunning() bool {
+	return ginkgo.CurrentSpecReport().LeafNodeType.Is(types.NodeTypeIt)
+}
+
+// addReportEntry attaches a hidden report entry to the current spec. Report
+// entries of all parallel processes are aggregated and passed to
+// reportAfterSuite, which makes them suitable for collecting changes that
+// must be applied once at the end of the suite.
+func addReportEntry(name string, value interface{}) {
+	ginkgo.AddReportEntry(name, value, ginkgo.ReportEntryVisibilityNever)
+}
+
+func decodeReportEntry(entry types.ReportEntry, v interface{}) error {
+	data, err := json.Marshal(entry.GetRawValue())
+	if err != nil {
+		return fmt.Errorf("json encode error: %w", err)
+	}
+
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("json decode error: %w", err)
+	}
+
+	return nil
+}
+
+func reportAfterSuite(report ginkgo.Report) {
+	// Snapshots must be written before obsolete snapshots are detected.
+	if err := flushReportedWrites(defaultFs, report); err != nil {
+		ginkgo.Fail(err.Error())
+	}
+
+	if err := writeInlineSnapshots(defaultFs, report); err != nil {
+		ginkgo.Fail(err.Error())
+	}
+
+	if err := checkDuplicateSnapshots(report); err != nil {
+		ginkgo.Fail(err.Error())
+	}
+
+	if err := checkObsoleteSnapshots(defaultFs, report, os.Stdout, getPruneObsolete()); err != nil {
+		ginkgo.Fail(err.Error())
+	}
+}
@@ -0,0 +1,99 @@
+package goldga
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+// nolint: gochecknoglobals
+var (
+	// ScrubRFC3339 replaces RFC 3339 timestamps with "<timestamp>".
+	ScrubRFC3339 Scrubber = &RegexpScrubber{
+		Pattern:     regexp.MustCompile(`\d{4}-\d{2}-\d{2}[Tt ]\d{2}:\d{2}:\d{2}(\.\d+)?([Zz]|[+-]\d{2}:\d{2})`),
+		Replacement: "<timestamp>",
+	}
+
+	// ScrubUUID replaces UUIDs with "<uuid>".
+	ScrubUUID Scrubber = &RegexpScrubber{
+		Pattern:     regexp.MustCompile(`[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`),
+		Replacement: "<uuid>",
+	}
+
+	// ScrubPointer replaces hexadecimal pointer addresses with "<pointer>".
+	ScrubPointer Scrubber = &RegexpScrubber{
+		Pattern:     regexp.MustCompile(`\b0x[0-9a-fA-F]{4,16}\b`),
+		Replacement: "<pointer>",
+	}
+
+	// ScrubDuration replaces durations formatted by time.Duration with "<duration>".
+	ScrubDuration Scrubber = &RegexpScrubber{
+		Pattern:     regexp.MustCompile(`\b(\d+(\.\d+)?(ns|us|µs|ms|s|m|h))+\b`),
+		Replacement: "<duration>",
+	}
+
+	// ScrubTempDir replaces paths in the temporary directory with "<tmp>". The
+	// first path element under the temporary directory is replaced as well,
+	// because it is usually random.
+	ScrubTempDir Scrubber = newPathScrubber(os.TempDir(), `([/\\][^/\\\s"']+)?`, "<tmp>")
+
+	// ScrubHomeDir replaces the home directory of the current user with "<home>".
+	ScrubHomeDir Scrubber = newPathScrubber(getHomeDir(), "", "<home>")
+)
+
+// Scrubber modifies the serialized content before it is compared and stored.
+type Scrubber interface {
+	Scrub(input []byte) ([]byte, error)
+}
+
+var _ Scrubber = (*RegexpScrubber)(nil)
+
+// RegexpScrubber replaces matches of Pattern with Replacement, which can
+// reference capture groups as in regexp.Regexp.ReplaceAll. A nil Pattern does
+// not match anything.
+type RegexpScrubber struct {
+	Pattern     *regexp.Regexp
+	Replacement string
+}
+
+func (r *RegexpScrubber) Scrub(input []byte) ([]byte, error) {
+	if r.Pattern == nil {
+		return input, nil
+	}
+
+	return r.Pattern.ReplaceAll(input, []byte(r.Replacement)), nil
+}
+
+// NewRegexpScrubber returns a scrubber which replaces matches of the pattern.
+func NewRegexpScrubber(pattern, replacement string) (*RegexpScrubber, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern: %w", err)
+	}
+
+	return &RegexpScrubber{
+		Pattern:     re,
+		Replacement: replacement,
+	}, nil
+}
+
+func getHomeDir() string {
+	dir, _ := os.UserHomeDir()
+
+	return dir
+}
+
+func newPathScrubber(path, suffix, replacement string) *RegexpScrubber {
+	path = filepath.Clean(path)
+
+	// Scrubbing the root directory would replace every absolute path.
+	if path == "." || path == string(filepath.Separator) {
+		return &RegexpScrubber{}
+	}
+
+	return &RegexpScrubber{
+		Pattern:     regexp.MustCompile(regexp.QuoteMeta(path) + suffix),
+		Replacement: replacement,
+	}
+}
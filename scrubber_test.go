@@ -0,0 +1,54 @@
+package goldga
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Scrubber", func() {
+	DescribeTable("built-in scrubbers", func(scrubber Scrubber, input, expected string) {
+		Expect(scrubber.Scrub([]byte(input))).To(Equal([]byte(expected)))
+	},
+		Entry("RFC3339", ScrubRFC3339, "at 2021-01-02T03:04:05Z", "at <timestamp>"),
+		Entry("RFC3339 with nanoseconds and offset", ScrubRFC3339, "2021-01-02T03:04:05.123456+08:00.", "<timestamp>."),
+		Entry("UUID", ScrubUUID, "id=123e4567-e89b-12d3-a456-426614174000", "id=<uuid>"),
+		Entry("pointer", ScrubPointer, "(*int)(0xc000012345)", "(*int)(<pointer>)"),
+		Entry("duration", ScrubDuration, "took 1m30.5s, 12ms and 3µs", "took <duration>, <duration> and <duration>"),
+		Entry("temp dir", ScrubTempDir, filepath.Join(os.TempDir(), "goldga-123", "foo"), filepath.Join("<tmp>", "foo")),
+		Entry("home dir", ScrubHomeDir, filepath.Join(getHomeDir(), "foo"), filepath.Join("<home>", "foo")),
+	)
+
+	Describe("RegexpScrubber", func() {
+		It("should support capture groups", func() {
+			scrubber := &RegexpScrubber{
+				Pattern:     regexp.MustCompile(`id=(\w)\w+`),
+				Replacement: "id=${1}xxx",
+			}
+			Expect(scrubber.Scrub([]byte("id=abc"))).To(Equal([]byte("id=axxx")))
+		})
+
+		It("should not change the input when pattern is nil", func() {
+			Expect((&RegexpScrubber{}).Scrub([]byte("abc"))).To(Equal([]byte("abc")))
+		})
+	})
+
+	Describe("NewRegexpScrubber", func() {
+		It("should return error when the pattern is invalid", func() {
+			_, err := NewRegexpScrubber("(", "")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("WithScrubbers", func() {
+		It("should scrub the serialized content", func() {
+			Expect("created at 2021-01-02T03:04:05Z").To(Match(
+				WithSerializer(&StringSerializer{}),
+				WithScrubbers(ScrubRFC3339),
+			))
+		})
+	})
+})
@@ -4,23 +4,23 @@ package goldga
 
 import (
 	"bufio"
+	"bytes"
+	"encoding/base64"
 	"errors"
 	"fmt"
-	"os"
+	"io"
 	"path/filepath"
 	"sort"
-	"time"
+	"unicode/utf8"
 
 	"github.com/BurntSushi/toml"
 	"github.com/spf13/afero"
 )
 
+// defaultFs reads files from the disk directly. Golden files may be modified
+// by other processes when specs run in parallel, so they must not be cached.
 // nolint: gochecknoglobals
-var defaultFs = afero.NewCacheOnReadFs(
-	afero.NewOsFs(),
-	afero.NewMemMapFs(),
-	time.Minute,
-)
+var defaultFs = afero.NewOsFs()
 
 type Storage interface {
 	Read() ([]byte, error)
@@ -32,6 +32,10 @@ var _ Storage = (*SingleStorage)(nil)
 type SingleStorage struct {
 	Path string
 	Fs   afero.Fs
+
+	// KeepLineEndings disables converting CRLF to LF on read and write. Line
+	// endings of content which is not valid UTF-8 are never converted.
+	KeepLineEndings bool
 }
 
 func (s *SingleStorage) Read() ([]byte, error) {
@@ -40,29 +44,93 @@ func (s *SingleStorage) Read() ([]byte, error) {
 		return nil, fmt.Errorf("failed to read file: %w", err)
 	}
 
-	return data, nil
+	return s.normalizeLineEndings(data), nil
 }
 
 func (s *SingleStorage) Write(data []byte) error {
-	if err := s.Fs.MkdirAll(filepath.Dir(s.Path), os.ModePerm); err != nil {
-		return fmt.Errorf("failed to create directories: %w", err)
+	if err := mkdirAll(s.Fs, filepath.Dir(s.Path)); err != nil {
+		return err
 	}
 
-	if err := afero.WriteFile(s.Fs, s.Path, data, os.ModePerm); err != nil {
+	if err := afero.WriteFile(s.Fs, s.Path, s.normalizeLineEndings(data), 0o644); err != nil {
 		return fmt.Errorf("failed to write file: %w", err)
 	}
 
 	return nil
 }
 
+func (s *SingleStorage) normalizeLineEndings(data []byte) []byte {
+	if s.KeepLineEndings || !utf8.Valid(data) {
+		return data
+	}
+
+	return normalizeLineEndings(data)
+}
+
+// FormatVersion is the version of the golden file format written by
+// SuiteStorage. Files written by older versions are upgraded when they are
+// written again, or with the migrate command.
+//
+// Version 1 files do not have a version field, and snapshots are always
+// written as multi-line literal strings. Version 2 escapes snapshots which
+// cannot be literal strings and adds the [binary] section. Version 3 adds the
+// [metadata] section, which older versions would drop when they write the file.
+const FormatVersion = 3
+
+// ErrUnsupportedFormatVersion is returned when a golden file is written by a
+// newer version of goldga.
+var ErrUnsupportedFormatVersion = errors.New("unsupported golden file format version")
+
 type suiteData struct {
+	Version   int               `toml:"version"`
 	Snapshots map[string]string `toml:"snapshots"`
+
+	// Binary contains base64 encoded snapshots which are not valid UTF-8 or
+	// are written in binary mode. They are moved to Snapshots after the file
+	// is decoded.
+	Binary map[string]string `toml:"binary"`
+
+	// Metadata is optional and only contains snapshots written with metadata.
+	Metadata map[string]*SnapshotMetadata `toml:"metadata"`
+
+	// binaryKeys contains snapshots which are always encoded as base64.
+	binaryKeys map[string]bool
 }
 
 func newSuiteData() *suiteData {
 	return &suiteData{
-		Snapshots: map[string]string{},
+		Snapshots:  map[string]string{},
+		Metadata:   map[string]*SnapshotMetadata{},
+		binaryKeys: map[string]bool{},
+	}
+}
+
+// upgrade converts data decoded from an older format to the current format.
+func (s *suiteData) upgrade() error {
+	switch {
+	case s.Version == 0:
+		// Version 1 files can be decoded as version 2 files.
+		s.Version = 1
+	case s.Version == 2:
+		// Version 2 files can be decoded as version 3 files, which only add
+		// the optional [metadata] section. The version is kept, so the file is
+		// reported as outdated until it is written again.
+	case s.Version > FormatVersion:
+		return fmt.Errorf("%w %d, the latest supported version is %d", ErrUnsupportedFormatVersion, s.Version, FormatVersion)
 	}
+
+	return nil
+}
+
+// setSnapshot sets the snapshot and removes its outdated metadata.
+func (s *suiteData) setSnapshot(key, value string, binary bool) {
+	s.Snapshots[key] = value
+	s.binaryKeys[key] = binary
+	delete(s.Metadata, key)
+}
+
+func (s *suiteData) isBinary(key string) bool {
+	return s.binaryKeys[key] || !utf8.ValidString(s.Snapshots[key])
 }
 
 func (s *suiteData) sortSnapshotKeys() []string {
@@ -83,9 +151,50 @@ type SuiteStorage struct {
 	Path string
 	Name string
 	Fs   afero.Fs
+
+	// Binary stores the snapshot as base64 in the [binary] section even if
+	// it is valid UTF-8. Line endings of binary snapshots are never converted.
+	Binary bool
+
+	// KeepLineEndings disables converting CRLF to LF in the golden file and
+	// in snapshots.
+	KeepLineEndings bool
+
+	// Batch buffers writes in memory until Flush is called, and reads
+	// snapshots from a parsed index of the file instead of decoding it every
+	// time. Match enables it for golden files in the default file system.
+	Batch bool
+
+	// index defaults to the index of the process.
+	index *snapshotIndex
 }
 
+func (s *SuiteStorage) getIndex() *snapshotIndex {
+	if s.index != nil {
+		return s.index
+	}
+
+	return defaultSnapshotIndex
+}
+
+func (s *SuiteStorage) normalizeLineEndings(data []byte) []byte {
+	if s.KeepLineEndings || s.Binary || !utf8.Valid(data) {
+		return data
+	}
+
+	return normalizeLineEndings(data)
+}
+
+// getSuiteData returns the parsed file, which must not be modified.
 func (s *SuiteStorage) getSuiteData() (*suiteData, error) {
+	if s.Batch {
+		return s.getIndex().get(s)
+	}
+
+	return s.readSuiteData()
+}
+
+func (s *SuiteStorage) readSuiteData() (*suiteData, error) {
 	exists, err := afero.Exists(s.Fs, s.Path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to check file exist: %w", err)
@@ -95,19 +204,42 @@ func (s *SuiteStorage) getSuiteData() (*suiteData, error) {
 		return nil, afero.ErrFileNotFound
 	}
 
-	file, err := s.Fs.Open(s.Path)
+	content, err := afero.ReadFile(s.Fs, s.Path)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open file: %w", err)
+		return nil, fmt.Errorf("failed to read file: %w", err)
 	}
 
-	defer file.Close()
+	// Golden files never contain CR, which is always escaped, so CRLF must
+	// come from the checkout, e.g. when core.autocrlf is enabled.
+	if !s.KeepLineEndings {
+		content = normalizeLineEndings(content)
+	}
 
 	data := newSuiteData()
 
-	if _, err := toml.NewDecoder(file).Decode(&data); err != nil {
+	if _, err := toml.NewDecoder(bytes.NewReader(content)).Decode(&data); err != nil {
 		return nil, fmt.Errorf("toml decode error: %w", err)
 	}
 
+	if err := data.upgrade(); err != nil {
+		return nil, err
+	}
+
+	for k, v := range data.Binary {
+		decoded, err := base64.StdEncoding.DecodeString(v)
+		if err != nil {
+			return nil, fmt.Errorf("base64 decode error in snapshot %q: %w", k, err)
+		}
+
+		data.Snapshots[k] = string(decoded)
+
+		if utf8.Valid(decoded) {
+			data.binaryKeys[k] = true
+		}
+	}
+
+	data.Binary = nil
+
 	return data, nil
 }
 
@@ -117,39 +249,101 @@ func (s *SuiteStorage) Read() ([]byte, error) {
 		return nil, err
 	}
 
-	if s, ok := data.Snapshots[s.Name]; ok {
-		return []byte(s), nil
+	if v, ok := data.Snapshots[s.Name]; ok {
+		if data.binaryKeys[s.Name] {
+			return []byte(v), nil
+		}
+
+		return s.normalizeLineEndings([]byte(v)), nil
 	}
 
 	return nil, afero.ErrFileNotFound
 }
 
 func (s *SuiteStorage) Write(input []byte) error {
+	return s.WriteWithMetadata(input, nil)
+}
+
+// Keys returns the sorted names of all snapshots stored in the file.
+func (s *SuiteStorage) Keys() ([]string, error) {
 	data, err := s.getSuiteData()
 	if err != nil {
-		if !errors.Is(err, afero.ErrFileNotFound) {
-			return err
+		return nil, err
+	}
+
+	return data.sortSnapshotKeys(), nil
+}
+
+// Delete removes the snapshots with the given names from the file.
+func (s *SuiteStorage) Delete(names ...string) error {
+	return s.updateSuiteData(func(data *suiteData) {
+		for _, name := range names {
+			delete(data.Snapshots, name)
+			delete(data.binaryKeys, name)
+			delete(data.Metadata, name)
 		}
+	})
+}
 
-		data = newSuiteData()
+// Version returns the format version of the file.
+func (s *SuiteStorage) Version() (int, error) {
+	data, err := s.getSuiteData()
+	if err != nil {
+		return 0, err
 	}
 
-	data.Snapshots[s.Name] = string(input)
+	return data.Version, nil
+}
 
-	if err := s.Fs.MkdirAll(filepath.Dir(s.Path), os.ModePerm); err != nil {
-		return fmt.Errorf("failed to create directories: %w", err)
+// Format sorts snapshots and encodes the file again in the current format,
+// e.g. after it was edited by hand or written by an older version.
+func (s *SuiteStorage) Format() error {
+	if _, err := s.getSuiteData(); err != nil {
+		return err
 	}
 
-	file, err := s.Fs.Create(s.Path)
+	return s.updateSuiteData(func(data *suiteData) {})
+}
+
+// updateSuiteData reads, modifies and writes the file while holding a lock,
+// so concurrent writers never overwrite snapshots of each other.
+func (s *SuiteStorage) updateSuiteData(update func(data *suiteData)) (err error) {
+	if err := mkdirAll(s.Fs, filepath.Dir(s.Path)); err != nil {
+		return err
+	}
+
+	unlock, err := lockFile(s.Fs, s.Path)
 	if err != nil {
-		return fmt.Errorf("failed to create file: %w", err)
+		return err
 	}
 
-	defer file.Close()
+	defer func() {
+		if unlockErr := unlock(); err == nil {
+			err = unlockErr
+		}
+	}()
 
+	data, err := s.readSuiteData()
+	if err != nil {
+		if !errors.Is(err, afero.ErrFileNotFound) {
+			return err
+		}
+
+		data = newSuiteData()
+	}
+
+	update(data)
+
+	return writeFileAtomic(s.Fs, s.Path, func(file afero.File) error {
+		return writeSuiteData(file, data)
+	})
+}
+
+func writeSuiteData(file io.Writer, data *suiteData) error {
 	w := bufio.NewWriter(file)
 	lines := []string{
 		"# Generated by goldga. DO NOT EDIT.",
+		fmt.Sprintf("version = %d", FormatVersion),
 		"[snapshots]",
 	}
 
@@ -160,18 +354,63 @@ func (s *SuiteStorage) Write(input []byte) error {
 		}
 	}
 
+	var binaryKeys []string
+
 	// Print snapshots
 	for _, k := range data.sortSnapshotKeys() {
-		v := data.Snapshots[k]
+		if data.isBinary(k) {
+			binaryKeys = append(binaryKeys, k)
 
-		if _, err := fmt.Fprintf(w, "%q = '''\n%s'''\n", k, v); err != nil {
-			return fmt.Errorf("snapshot write error: %w", err)
+			continue
+		}
+
+		if err := writeSnapshotEntry(w, k, data.Snapshots[k]); err != nil {
+			return err
 		}
 	}
 
+	// Print binary snapshots as base64 strings
+	if len(binaryKeys) > 0 {
+		if _, err := fmt.Fprintln(w, "[binary]"); err != nil {
+			return fmt.Errorf("header write error: %w", err)
+		}
+
+		for _, k := range binaryKeys {
+			v := base64.StdEncoding.EncodeToString([]byte(data.Snapshots[k]))
+
+			if _, err := fmt.Fprintf(w, "%s = %s\n", quoteTOMLString(k), quoteTOMLString(v)); err != nil {
+				return fmt.Errorf("snapshot write error: %w", err)
+			}
+		}
+	}
+
+	if err := writeMetadataEntries(w, data); err != nil {
+		return err
+	}
+
 	if err := w.Flush(); err != nil {
 		return fmt.Errorf("flush error: %w", err)
 	}
 
 	return nil
 }
+
+// writeSnapshotEntry writes the snapshot as a multi-line literal string, which
+// keeps the content readable. Snapshots which cannot be represented that way,
+// e.g. containing triple quotes or control characters, are written as basic
+// strings.
+func writeSnapshotEntry(w io.Writer, key, value string) error {
+	var err error
+
+	if canUseMultilineLiteral(value) {
+		_, err = fmt.Fprintf(w, "%s = '''\n%s'''\n", quoteTOMLString(key), value)
+	} else {
+		_, err = fmt.Fprintf(w, "%s = %s\n", quoteTOMLString(key), quoteTOMLString(value))
+	}
+
+	if err != nil {
+		return fmt.Errorf("snapshot write error: %w", err)
+	}
+
+	return nil
+}
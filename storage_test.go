@@ -2,8 +2,10 @@ package goldga
 
 import (
 	"bytes"
+	"fmt"
 	"os"
 	"path/filepath"
+	"sync"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -69,6 +71,26 @@ var _ = Describe("SingleStorage", func() {
 		It("should not return error", func() {
 			Expect(err).NotTo(HaveOccurred())
 		})
+
+		When("file contains CRLF", func() {
+			BeforeEach(func() {
+				Expect(afero.WriteFile(fs, storage.Path, []byte("a\r\nb\r\n"), 0o644)).To(Succeed())
+			})
+
+			It("should convert line endings", func() {
+				Expect(output).To(Equal([]byte("a\nb\n")))
+			})
+
+			When("KeepLineEndings = true", func() {
+				BeforeEach(func() {
+					storage.KeepLineEndings = true
+				})
+
+				It("should return raw bytes", func() {
+					Expect(output).To(Equal([]byte("a\r\nb\r\n")))
+				})
+			})
+		})
 	})
 
 	Context("Write", func() {
@@ -172,6 +194,59 @@ var _ = Describe("SuiteStorage", func() {
 
 				testNotFound()
 			})
+
+			When("file is checked out with CRLF", func() {
+				BeforeEach(func() {
+					writeFile("[snapshots]\r\n\"Suite test\" = '''\r\na\r\nb\r\n'''\r\n")
+				})
+
+				It("should convert line endings", func() {
+					Expect(output).To(Equal([]byte("a\nb\n")))
+				})
+			})
+
+			When("file does not have a version", func() {
+				BeforeEach(func() {
+					writeFile("[snapshots]\n\"Suite test\" = '''\nfoo'''\n")
+				})
+
+				It("should read the file as version 1", func() {
+					Expect(output).To(Equal([]byte("foo")))
+					Expect(storage.Version()).To(Equal(1))
+				})
+
+				It("should upgrade the file when it is formatted", func() {
+					Expect(storage.Format()).To(Succeed())
+					Expect(storage.Version()).To(Equal(FormatVersion))
+				})
+			})
+
+			When("file is written in version 2", func() {
+				BeforeEach(func() {
+					writeFile("version = 2\n[snapshots]\n\"Suite test\" = \"foo\"\n")
+				})
+
+				It("should read the file", func() {
+					Expect(output).To(Equal([]byte("foo")))
+					Expect(storage.Version()).To(Equal(2))
+				})
+
+				It("should upgrade the file when it is formatted", func() {
+					Expect(storage.Format()).To(Succeed())
+					Expect(storage.Version()).To(Equal(3))
+				})
+			})
+
+			When("file is written by a newer version", func() {
+				BeforeEach(func() {
+					writeFile("version = 999\n[snapshots]\n\"Suite test\" = \"foo\"\n")
+				})
+
+				It("should return unsupported version error", func() {
+					Expect(err).To(MatchError(ErrUnsupportedFormatVersion))
+					Expect(storage.Write([]byte("bar"))).To(MatchError(ErrUnsupportedFormatVersion))
+				})
+			})
 		})
 
 		When("file not exist", func() {
@@ -204,6 +279,7 @@ Z = "zzz"`)
 
 			It("should write the file", func() {
 				Expect(readFile()).To(Equal(`# Generated by goldga. DO NOT EDIT.
+version = 3
 [snapshots]
 "A" = '''
 abc'''
@@ -222,6 +298,7 @@ zzz'''
 		When("file not exist", func() {
 			It("should write the file", func() {
 				Expect(readFile()).To(Equal(`# Generated by goldga. DO NOT EDIT.
+version = 3
 [snapshots]
 "Suite test" = '''
 bar'''
@@ -232,5 +309,111 @@ bar'''
 				Expect(err).NotTo(HaveOccurred())
 			})
 		})
+
+		DescribeTable("round trip", func(input string) {
+			storage.KeepLineEndings = true
+			Expect(storage.Write([]byte(input))).To(Succeed())
+
+			other := &SuiteStorage{Path: storage.Path, Name: "other", Fs: fs, KeepLineEndings: true}
+			Expect(other.Write([]byte("other"))).To(Succeed())
+
+			Expect(storage.Read()).To(Equal([]byte(input)))
+			Expect(other.Read()).To(Equal([]byte("other")))
+		},
+			Entry("empty", ""),
+			Entry("multi-line", "a\nb\n"),
+			Entry("leading newline", "\nabc"),
+			Entry("triple quotes", "a'''b"),
+			Entry("trailing quote", "a'"),
+			Entry("quotes and backslashes", `"\\'`),
+			Entry("carriage return", "a\r\nb\rc"),
+			Entry("control characters", "a\x00\x01\x1b\x7f\tb"),
+			Entry("unicode", "中文 ✓"),
+			Entry("invalid UTF-8", "a\xff\xfeb"),
+		)
+
+		When("snapshot contains triple quotes", func() {
+			It("should write a basic string", func() {
+				storage.Name = `Quote "test"`
+				Expect(storage.Write([]byte("a'''b"))).To(Succeed())
+				Expect(readFile()).To(ContainSubstring(`"Quote \"test\"" = "a'''b"`))
+			})
+		})
+
+		When("snapshot is not valid UTF-8", func() {
+			It("should write a base64 string", func() {
+				Expect(storage.Write([]byte{0xff, 0xfe})).To(Succeed())
+				Expect(readFile()).To(HaveSuffix("[binary]\n\"Suite test\" = \"//4=\"\n"))
+			})
+		})
+
+		When("snapshot contains CRLF", func() {
+			It("should convert line endings", func() {
+				Expect(storage.Write([]byte("a\r\nb"))).To(Succeed())
+				Expect(storage.Read()).To(Equal([]byte("a\nb")))
+			})
+
+			It("should keep line endings when KeepLineEndings = true", func() {
+				storage.KeepLineEndings = true
+				Expect(storage.Write([]byte("a\r\nb"))).To(Succeed())
+				Expect(storage.Read()).To(Equal([]byte("a\r\nb")))
+			})
+		})
+
+		When("binary mode is enabled", func() {
+			BeforeEach(func() {
+				storage.Binary = true
+			})
+
+			It("should write a base64 string", func() {
+				Expect(readFile()).To(HaveSuffix("[binary]\n\"Suite test\" = \"YmFy\"\n"))
+			})
+
+			It("should keep the encoding when other snapshots are written", func() {
+				other := &SuiteStorage{Path: storage.Path, Name: "other", Fs: fs}
+				Expect(other.Write([]byte("other"))).To(Succeed())
+				Expect(readFile()).To(HaveSuffix("[binary]\n\"Suite test\" = \"YmFy\"\n"))
+				Expect(storage.Read()).To(Equal(input))
+			})
+		})
+
+		When("file is formatted", func() {
+			BeforeEach(func() {
+				writeFile("[snapshots]\nZ = \"z\"\nA = \"a\"\n")
+			})
+
+			It("should sort and encode snapshots", func() {
+				Expect(storage.Format()).To(Succeed())
+				Expect(readFile()).To(HavePrefix("# Generated by goldga. DO NOT EDIT.\nversion = 3\n[snapshots]\n\"A\" = '''\na'''\n\"Suite test\" = '''\nbar'''\n\"Z\""))
+			})
+		})
+
+		When("written concurrently", func() {
+			It("should keep all snapshots", func() {
+				var wg sync.WaitGroup
+				names := []string{"Suite test"}
+
+				for i := 0; i < 20; i++ {
+					name := fmt.Sprintf("Concurrent %02d", i)
+					names = append(names, name)
+					wg.Add(1)
+
+					go func() {
+						defer GinkgoRecover()
+						defer wg.Done()
+
+						s := &SuiteStorage{Path: storage.Path, Name: name, Fs: fs}
+						Expect(s.Write([]byte(name))).To(Succeed())
+					}()
+				}
+
+				wg.Wait()
+				Expect(storage.Keys()).To(ConsistOf(names))
+			})
+
+			It("should remove the lock file", func() {
+				Expect(afero.Exists(fs, storage.Path+lockFileSuffix)).To(BeFalse())
+			})
+		})
 	})
 })
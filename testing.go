@@ -0,0 +1,372 @@
+package goldga
+
+import (
+	"flag"
+	"io"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/spf13/afero"
+)
+
+// nolint: gochecknoglobals
+var (
+	testTrackers   = map[testing.TB]*testTracker{}
+	testTrackersMu sync.Mutex
+
+	testSnapshots = &snapshotSet{}
+)
+
+// MatchT returns a matcher for tests which do not run in Ginkgo, e.g. plain
+// `go test` with gomega.NewWithT. The golden file is derived from the file of
+// the caller and the snapshot is named after t.Name(). Snapshots are numbered
+// in the order of MatchT calls in a test like those of Match.
+//
+// Snapshots are written when the test finishes. Golden files in the default
+// file system are batched like those of Match, so TestMain must call Flush or
+// ReportObsoleteT after all tests. Obsolete snapshots of the test
+// and its subtests are reported at the same time, unless the test failed or
+// was filtered with -run or -skip. Snapshots of deleted or renamed tests are
+// not owned by any test, so they are only reported by ReportObsoleteT.
+func MatchT(t testing.TB, options ...Option) *Matcher {
+	t.Helper()
+
+	_, file, line, ok := runtime.Caller(1)
+	if !ok {
+		panic("failed to get the caller of MatchT")
+	}
+
+	m := newMatcher(resolveGoldenPath(getConfig().getPathResolver(), file), t.Name(), options)
+	m.sourceFile, m.sourceLine = file, line
+	m.applyPathResolver(file)
+
+	tracker := getTestTracker(t)
+
+	if current, ok := getStorageName(m.Storage); ok && current == t.Name() {
+		numberSnapshot(m, tracker.counter.next(t.Name(), current))
+	}
+
+	if s, ok := m.Storage.(*SuiteStorage); ok {
+		if s.Fs == defaultFs {
+			s.Batch = true
+		}
+
+		m.Storage = tracker.track(s)
+
+		// The fallback is only marked as used when it is read.
+		if fallback, ok := m.fallback.(*SuiteStorage); ok {
+			m.fallback = &testStorage{SuiteStorage: fallback, tracker: tracker}
+		}
+	}
+
+	return m
+}
+
+// ReportObsoleteT reports snapshots which were not matched by any test in
+// golden files used by MatchT, e.g. snapshots of deleted tests. Obsolete
+// snapshots are removed when GOLDGA_PRUNE is set. It must be called after all
+// tests passed, e.g. in TestMain. It calls Flush first, and only flushes when
+// tests were filtered with -run or -skip.
+//
+//	func TestMain(m *testing.M) {
+//		code := m.Run()
+//		report := goldga.Flush
+//
+//		if code == 0 {
+//			report = func() error { return goldga.ReportObsoleteT(os.Stdout) }
+//		}
+//
+//		if err := report(); err != nil {
+//			log.Fatal(err)
+//		}
+//
+//		os.Exit(code)
+//	}
+func ReportObsoleteT(w io.Writer) error {
+	if err := Flush(); err != nil {
+		return err
+	}
+
+	if isTestFiltered() {
+		return nil
+	}
+
+	return testSnapshots.reportObsolete(w, getPruneObsolete())
+}
+
+type snapshotSet struct {
+	mu    sync.Mutex
+	data  map[string]map[string]bool
+	files map[string]afero.Fs
+}
+
+func (s *snapshotSet) add(fs afero.Fs, path, name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.data == nil {
+		s.data = map[string]map[string]bool{}
+		s.files = map[string]afero.Fs{}
+	}
+
+	if s.data[path] == nil {
+		s.data[path] = map[string]bool{}
+	}
+
+	s.data[path][name] = true
+	s.files[path] = fs
+}
+
+// reportObsolete checks every golden file in the set against all snapshots
+// matched in it.
+func (s *snapshotSet) reportObsolete(w io.Writer, prune bool) error {
+	s.mu.Lock()
+	storages := make([]*SuiteStorage, 0, len(s.files))
+
+	for path, fs := range s.files {
+		storages = append(storages, &SuiteStorage{Path: path, Fs: fs})
+	}
+
+	s.mu.Unlock()
+
+	sort.Slice(storages, func(i, j int) bool {
+		return storages[i].Path < storages[j].Path
+	})
+
+	for _, storage := range storages {
+		if err := handleObsoleteSnapshots(w, storage, s.get(storage.Path), nil, prune); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *snapshotSet) get(path string) map[string]bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := make(map[string]bool, len(s.data[path]))
+
+	for k, v := range s.data[path] {
+		result[k] = v
+	}
+
+	return result
+}
+
+type testTracker struct {
+	t        testing.TB
+	mu       sync.Mutex
+	storages map[string]*SuiteStorage
+	writes   map[string]map[string]*snapshotWrite
+	counter  specCounter
+}
+
+func getTestTracker(t testing.TB) *testTracker {
+	testTrackersMu.Lock()
+	defer testTrackersMu.Unlock()
+
+	if tracker, ok := testTrackers[t]; ok {
+		return tracker
+	}
+
+	tracker := &testTracker{
+		t:        t,
+		storages: map[string]*SuiteStorage{},
+		writes:   map[string]map[string]*snapshotWrite{},
+	}
+	testTrackers[t] = tracker
+	t.Cleanup(tracker.cleanup)
+
+	return tracker
+}
+
+func (tr *testTracker) track(s *SuiteStorage) *testStorage {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+
+	if _, ok := tr.storages[s.Path]; !ok {
+		tr.storages[s.Path] = &SuiteStorage{Path: s.Path, Fs: s.Fs, Batch: s.Batch, index: s.index}
+	}
+
+	testSnapshots.add(s.Fs, s.Path, s.Name)
+
+	return &testStorage{
+		SuiteStorage: s,
+		tracker:      tr,
+	}
+}
+
+func (tr *testTracker) read(path, name string) (*snapshotWrite, bool) {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+
+	w, ok := tr.writes[path][name]
+
+	return w, ok
+}
+
+func (tr *testTracker) write(path, name string, w *snapshotWrite) {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+
+	if tr.writes[path] == nil {
+		tr.writes[path] = map[string]*snapshotWrite{}
+	}
+
+	tr.writes[path][name] = w
+}
+
+func (tr *testTracker) sortedPaths() []string {
+	paths := make([]string, 0, len(tr.storages))
+
+	for path := range tr.storages {
+		paths = append(paths, path)
+	}
+
+	sort.Strings(paths)
+
+	return paths
+}
+
+func (tr *testTracker) cleanup() {
+	testTrackersMu.Lock()
+	delete(testTrackers, tr.t)
+	testTrackersMu.Unlock()
+
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+
+	paths := tr.sortedPaths()
+
+	for _, path := range paths {
+		if err := tr.flushWrites(tr.storages[path], tr.writes[path]); err != nil {
+			tr.t.Errorf("goldga: failed to write %s: %v", path, err)
+		}
+	}
+
+	if tr.t.Failed() || tr.t.Skipped() || isTestFiltered() {
+		return
+	}
+
+	w := &testLogWriter{t: tr.t}
+	prune := getPruneObsolete()
+
+	for _, path := range paths {
+		if err := handleObsoleteSnapshots(w, tr.storages[path], testSnapshots.get(path), tr.ownsSnapshot, prune); err != nil {
+			tr.t.Errorf("goldga: %v", err)
+		}
+	}
+}
+
+// flushWrites buffers writes of the test in the index when batching is
+// enabled, otherwise writes them to the golden file at once.
+func (tr *testTracker) flushWrites(storage *SuiteStorage, writes map[string]*snapshotWrite) error {
+	if len(writes) == 0 {
+		return nil
+	}
+
+	names := make([]string, 0, len(writes))
+
+	for name := range writes {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	if storage.Batch {
+		for _, name := range names {
+			if err := storage.getIndex().write(storage.Fs, writes[name]); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	return storage.updateSuiteData(func(data *suiteData) {
+		for _, name := range names {
+			writes[name].apply(data)
+		}
+	})
+}
+
+// ownsSnapshot returns true if the snapshot belongs to the test or its
+// subtests. Names of tests never contain spaces, so a key with a space after
+// the name is a numbered snapshot or has a description.
+func (tr *testTracker) ownsSnapshot(key string) bool {
+	name := tr.t.Name()
+
+	return key == name || strings.HasPrefix(key, name+"/") || strings.HasPrefix(key, name+" ")
+}
+
+// isTestFiltered returns true when only a subset of tests runs, in which case
+// snapshots of the other tests would be reported as obsolete.
+func isTestFiltered() bool {
+	for _, name := range []string{"test.run", "test.skip"} {
+		if f := flag.Lookup(name); f != nil && f.Value.String() != "" {
+			return true
+		}
+	}
+
+	return false
+}
+
+type testLogWriter struct {
+	t testing.TB
+}
+
+func (w *testLogWriter) Write(p []byte) (int, error) {
+	w.t.Log(strings.TrimSuffix(string(p), "\n"))
+
+	return len(p), nil
+}
+
+var (
+	_ Storage         = (*testStorage)(nil)
+	_ MetadataStorage = (*testStorage)(nil)
+)
+
+// testStorage buffers writes until the test finishes.
+type testStorage struct {
+	*SuiteStorage
+
+	tracker *testTracker
+}
+
+func (s *testStorage) Read() ([]byte, error) {
+	if w, ok := s.tracker.read(s.Path, s.Name); ok {
+		return w.Data, nil
+	}
+
+	return s.SuiteStorage.Read()
+}
+
+func (s *testStorage) Write(data []byte) error {
+	return s.WriteWithMetadata(data, nil)
+}
+
+func (s *testStorage) ReadMetadata() (*SnapshotMetadata, error) {
+	if w, ok := s.tracker.read(s.Path, s.Name); ok {
+		return w.Metadata, nil
+	}
+
+	return s.SuiteStorage.ReadMetadata()
+}
+
+func (s *testStorage) WriteWithMetadata(data []byte, metadata *SnapshotMetadata) error {
+	s.tracker.write(s.Path, s.Name, &snapshotWrite{
+		Path:            s.Path,
+		Name:            s.Name,
+		Data:            data,
+		Binary:          s.Binary,
+		KeepLineEndings: s.KeepLineEndings,
+		Metadata:        metadata,
+	})
+
+	return nil
+}
@@ -0,0 +1,237 @@
+package goldga
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type fakeT struct {
+	testing.TB
+
+	name     string
+	failed   bool
+	logs     []string
+	errors   []string
+	cleanups []func()
+}
+
+func (f *fakeT) Helper() {}
+
+func (f *fakeT) Name() string {
+	return f.name
+}
+
+func (f *fakeT) Failed() bool {
+	return f.failed || len(f.errors) > 0
+}
+
+func (f *fakeT) Skipped() bool {
+	return false
+}
+
+func (f *fakeT) Log(args ...interface{}) {
+	f.logs = append(f.logs, fmt.Sprint(args...))
+}
+
+func (f *fakeT) Errorf(format string, args ...interface{}) {
+	f.errors = append(f.errors, fmt.Sprintf(format, args...))
+}
+
+func (f *fakeT) Cleanup(fn func()) {
+	f.cleanups = append(f.cleanups, fn)
+}
+
+func (f *fakeT) finish() {
+	for i := len(f.cleanups) - 1; i >= 0; i-- {
+		f.cleanups[i]()
+	}
+}
+
+var _ = Describe("MatchT", func() {
+	var (
+		fs *tempFs
+		t  *fakeT
+	)
+
+	BeforeEach(func() {
+		fs = newTempFs()
+		t = &fakeT{name: "TestFoo"}
+	})
+
+	AfterEach(func() {
+		fs.Teardown()
+	})
+
+	newStorage := func(name string) *SuiteStorage {
+		return &SuiteStorage{
+			Path: filepath.Join(fs.path, "foo.golden"),
+			Name: name,
+			Fs:   fs,
+		}
+	}
+
+	match := func(t testing.TB, name string, actual interface{}) bool {
+		success, err := MatchT(t,
+			WithStorage(newStorage(name)),
+			WithUpdateMode(UpdateNew),
+			WithCI(false),
+			WithReview(false),
+		).Match(actual)
+		Expect(err).NotTo(HaveOccurred())
+
+		return success
+	}
+
+	It("should use the caller file and the test name", func() {
+		matcher := MatchT(t, WithLayout(LayoutSuite))
+		Expect(matcher.Storage).To(BeAssignableToTypeOf(&testStorage{}))
+
+		storage := matcher.Storage.(*testStorage)
+		Expect(storage.Path).To(Equal(filepath.Join("testdata", "testing.golden")))
+		Expect(storage.Name).To(Equal("TestFoo 1"))
+
+		t.cleanups = nil
+	})
+
+	It("should write snapshots when the test finishes", func() {
+		Expect(match(t, "Foo", "a")).To(BeTrue())
+		Expect(match(t, "Foo", "a")).To(BeTrue())
+		Expect(match(t, "Foo", "b")).To(BeFalse())
+
+		_, err := newStorage("Foo").Read()
+		Expect(err).To(HaveOccurred())
+
+		t.finish()
+		Expect(t.errors).To(BeEmpty())
+		Expect(newStorage("Foo").Keys()).To(Equal([]string{"Foo"}))
+	})
+
+	It("should number snapshots in a test", func() {
+		Expect(match(t, "TestFoo", "a")).To(BeTrue())
+		Expect(match(t, "TestFoo", "b")).To(BeTrue())
+
+		t.finish()
+		Expect(t.errors).To(BeEmpty())
+		Expect(newStorage("TestFoo").Keys()).To(Equal([]string{"TestFoo 1", "TestFoo 2"}))
+	})
+
+	It("should fall back to the snapshot without a number", func() {
+		Expect(newStorage("TestFoo").Write([]byte("(string) (len=1) \"a\"\n"))).To(Succeed())
+		Expect(match(t, "TestFoo", "a")).To(BeTrue())
+
+		t.finish()
+		Expect(t.errors).To(BeEmpty())
+		Expect(t.logs).To(BeEmpty())
+		Expect(newStorage("TestFoo").Keys()).To(Equal([]string{"TestFoo"}))
+	})
+
+	It("should batch writes in the index until it is flushed", func() {
+		index := &snapshotIndex{}
+		storage := newStorage("TestFoo")
+		storage.Batch = true
+		storage.index = index
+
+		success, err := MatchT(t,
+			WithStorage(storage),
+			WithSerializer(&StringSerializer{}),
+			WithUpdateMode(UpdateNew),
+			WithCI(false),
+			WithReview(false),
+		).Match("a")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(success).To(BeTrue())
+
+		t.finish()
+		Expect(t.errors).To(BeEmpty())
+
+		_, err = newStorage("TestFoo 1").Read()
+		Expect(err).To(HaveOccurred())
+
+		Expect(index.flush(false)).To(Succeed())
+		Expect(newStorage("TestFoo 1").Read()).To(Equal([]byte("a")))
+	})
+
+	When("snapshots of the test are not used", func() {
+		BeforeEach(func() {
+			for _, name := range []string{"TestFoo/a", "TestFoo/b", "TestFooBar"} {
+				Expect(newStorage(name).Write([]byte(name))).To(Succeed())
+			}
+
+			sub := &fakeT{name: "TestFoo/a"}
+			match(sub, "TestFoo/a", "TestFoo/a")
+			sub.finish()
+		})
+
+		JustBeforeEach(func() {
+			match(t, "TestFoo", "foo")
+		})
+
+		It("should report obsolete snapshots", func() {
+			if os.Getenv("GOLDGA_PRUNE") != "" {
+				Skip("obsolete snapshots are removed instead")
+			}
+
+			t.finish()
+			Expect(t.logs).To(ContainElement(ContainSubstring("found 1 obsolete snapshot(s)")))
+			Expect(t.logs).To(ContainElement(ContainSubstring(`"TestFoo/b"`)))
+			Expect(t.logs).NotTo(ContainElement(ContainSubstring(`"TestFooBar"`)))
+		})
+
+		It("should not report when the test failed", func() {
+			t.failed = true
+			t.finish()
+			Expect(t.logs).To(BeEmpty())
+		})
+	})
+})
+
+var _ = Describe("snapshotSet", func() {
+	var (
+		fs  *tempFs
+		set *snapshotSet
+		buf *bytes.Buffer
+	)
+
+	newStorage := func(name string) *SuiteStorage {
+		return &SuiteStorage{
+			Path: filepath.Join(fs.path, "foo.golden"),
+			Name: name,
+			Fs:   fs,
+		}
+	}
+
+	BeforeEach(func() {
+		fs = newTempFs()
+		set = &snapshotSet{}
+		buf = &bytes.Buffer{}
+
+		for _, name := range []string{"TestFoo", "TestDeleted", "TestDeleted/sub"} {
+			Expect(newStorage(name).Write([]byte(name))).To(Succeed())
+		}
+
+		set.add(fs, newStorage("").Path, "TestFoo")
+	})
+
+	AfterEach(func() {
+		fs.Teardown()
+	})
+
+	It("should report snapshots of deleted tests", func() {
+		Expect(set.reportObsolete(buf, false)).To(Succeed())
+		Expect(buf.String()).To(ContainSubstring("found 2 obsolete snapshot(s)"))
+		Expect(buf.String()).To(ContainSubstring(`"TestDeleted"`))
+		Expect(buf.String()).To(ContainSubstring(`"TestDeleted/sub"`))
+		Expect(buf.String()).NotTo(ContainSubstring(`"TestFoo"`))
+	})
+
+	It("should remove obsolete snapshots when prune is enabled", func() {
+		Expect(set.reportObsolete(buf, true)).To(Succeed())
+		Expect(newStorage("").Keys()).To(Equal([]string{"TestFoo"}))
+	})
+})
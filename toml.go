@@ -0,0 +1,65 @@
+package goldga
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// quoteTOMLString returns a TOML basic string. Unlike strconv.Quote, it only
+// uses escape sequences supported by TOML. The input must be valid UTF-8.
+func quoteTOMLString(s string) string {
+	var b strings.Builder
+
+	b.Grow(len(s) + 2)
+	b.WriteByte('"')
+
+	for _, r := range s {
+		switch r {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\b':
+			b.WriteString(`\b`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\f':
+			b.WriteString(`\f`)
+		case '\r':
+			b.WriteString(`\r`)
+		default:
+			if isTOMLControlChar(r) {
+				fmt.Fprintf(&b, `\u%04X`, r)
+			} else {
+				b.WriteRune(r)
+			}
+		}
+	}
+
+	b.WriteByte('"')
+
+	return b.String()
+}
+
+func isTOMLControlChar(r rune) bool {
+	return (r < 0x20 && r != '\t') || r == 0x7f
+}
+
+// canUseMultilineLiteral returns true if the string can be written as a TOML
+// multi-line literal string without changing its content.
+func canUseMultilineLiteral(s string) bool {
+	if !utf8.ValidString(s) || strings.Contains(s, "'''") || strings.HasSuffix(s, "'") {
+		return false
+	}
+
+	for _, r := range s {
+		if r != '\n' && isTOMLControlChar(r) {
+			return false
+		}
+	}
+
+	return true
+}
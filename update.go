@@ -0,0 +1,64 @@
+package goldga
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+)
+
+// UpdateMode determines which snapshots are written to the storage.
+type UpdateMode string
+
+const (
+	// UpdateNone never writes snapshots. Missing snapshots fail the assertion.
+	UpdateNone UpdateMode = "none"
+
+	// UpdateNew writes missing snapshots only. This is the default mode.
+	UpdateNew UpdateMode = "new"
+
+	// UpdateFailed writes missing snapshots and rewrites mismatched snapshots.
+	UpdateFailed UpdateMode = "failed"
+
+	// UpdateAll rewrites every snapshot.
+	UpdateAll UpdateMode = "all"
+)
+
+// ParseUpdateMode parses an update mode. Boolean values are accepted for
+// backward compatibility, true means UpdateAll and false means UpdateNew.
+func ParseUpdateMode(s string) (UpdateMode, error) {
+	switch mode := UpdateMode(s); mode {
+	case UpdateNone, UpdateNew, UpdateFailed, UpdateAll:
+		return mode, nil
+	case "":
+		return UpdateNew, nil
+	}
+
+	update, err := strconv.ParseBool(s)
+	if err != nil {
+		return "", fmt.Errorf("invalid update mode %q", s)
+	}
+
+	if update {
+		return UpdateAll, nil
+	}
+
+	return UpdateNew, nil
+}
+
+// getUpdateMode returns the update mode set by the UPDATE_GOLDEN environment
+// variable, or fallback when it is not set. explicit is true when the mode is
+// set by the environment variable.
+func getUpdateMode(fallback UpdateMode) (mode UpdateMode, explicit bool) {
+	value := os.Getenv("UPDATE_GOLDEN")
+
+	if value == "" && fallback != "" {
+		return fallback, false
+	}
+
+	mode, err := ParseUpdateMode(value)
+	if err != nil {
+		panic(fmt.Errorf("UPDATE_GOLDEN: %w", err))
+	}
+
+	return mode, value != ""
+}
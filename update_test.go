@@ -0,0 +1,27 @@
+package goldga
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ParseUpdateMode", func() {
+	DescribeTable("valid", func(input string, expected UpdateMode) {
+		Expect(ParseUpdateMode(input)).To(Equal(expected))
+	},
+		Entry("empty", "", UpdateNew),
+		Entry("none", "none", UpdateNone),
+		Entry("new", "new", UpdateNew),
+		Entry("failed", "failed", UpdateFailed),
+		Entry("all", "all", UpdateAll),
+		Entry("true", "true", UpdateAll),
+		Entry("1", "1", UpdateAll),
+		Entry("false", "false", UpdateNew),
+		Entry("0", "0", UpdateNew),
+	)
+
+	It("should return error when the mode is invalid", func() {
+		_, err := ParseUpdateMode("foo")
+		Expect(err).To(HaveOccurred())
+	})
+})